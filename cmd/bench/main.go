@@ -0,0 +1,106 @@
+package main
+
+import (
+	"BachelorThesis/engine/bench"
+	"BachelorThesis/engine/config"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bench repeats the FPM experiment of the thesis headlessly: every combination of the variants on every
+// object count of the ladder, with the time of every stage written to CSV and JSON
+func main() {
+	base := config.Default()
+
+	ladder := flag.String("ladder", "1024,2048,4096,8192,16384,32768", "object counts, comma separated")
+	frames := flag.Int("frames", 60, "measured frames of a trial")
+	warmup := flag.Int("warmup", 10, "frames of a trial before the measured ones")
+	trials := flag.Int("trials", 3, "trials of every combination and object count")
+	seed := flag.Int64("seed", 1, "seed of the scene, every trial starts from the same one")
+	maxFrame := flag.Duration("max-frame", 0, "skip the larger counts of a combination once its frame is slower, 0 for no limit")
+
+	flag.StringVar(&base.Broad, "broad", "sap", "broad phase")
+	broadVariants := flag.String("broad-variants", "", "broad phase variants, all of them by default")
+	flag.StringVar(&base.Narrow, "narrow", "sat", "narrow phase")
+	narrowVariants := flag.String("narrow-variants", "", "narrow phase variants, all of them by default")
+	resolvers := flag.String("resolvers", "pgs,tgs,msi,lcp", "resolvers, every variant of each is measured")
+	pipelines := flag.String("pipelines", "sequential,parallel", "pipelines")
+	flag.StringVar(&base.Integrator, "integrator", base.Integrator, "integrator")
+	flag.BoolVar(&base.WorldBox, "worldbox", false, "enclose the scene in a world box")
+
+	csvPath := flag.String("csv", "bench.csv", "CSV output, empty to skip")
+	jsonPath := flag.String("json", "bench.json", "JSON output, empty to skip")
+	flag.Parse()
+
+	options := bench.Options{Frames: *frames, Warmup: *warmup, Trials: *trials, Seed: *seed, MaxFrame: *maxFrame}
+	for _, count := range split(*ladder) {
+		objects, err := strconv.Atoi(count)
+		if err != nil || objects <= 0 {
+			log.Fatalf("Invalid object count %q in the ladder", count)
+		}
+		options.Ladder = append(options.Ladder, objects)
+	}
+
+	broad := split(*broadVariants)
+	if len(broad) == 0 {
+		broad = config.BroadVariants(base.Broad)
+	}
+	narrow := split(*narrowVariants)
+	if len(narrow) == 0 {
+		narrow = config.NarrowVariants(base.Narrow)
+	}
+
+	combinations, err := bench.Combinations(base, broad, narrow, split(*resolvers), split(*pipelines))
+	if err != nil {
+		log.Fatalf("Invalid combination: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	records, err := bench.Run(combinations, options, ctx)
+	if err != nil {
+		// the finished trials are still written
+		log.Printf("Benchmark stopped: %v", err)
+	}
+	log.Printf("Benchmark of %d combinations took %v, %d trials recorded", len(combinations), time.Since(start), len(records))
+
+	if *csvPath != "" {
+		write(*csvPath, records, bench.WriteCSV)
+	}
+	if *jsonPath != "" {
+		write(*jsonPath, records, bench.WriteJSON)
+	}
+}
+
+func write(path string, records []bench.Record, writer func(io.Writer, []bench.Record) error) {
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+
+	if err := writer(file, records); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+	log.Printf("Results written to %s", path)
+}
+
+// split parses a comma separated list, empty items are dropped
+func split(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"BachelorThesis/engine/config"
+	"BachelorThesis/engine/headless"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// without limits the run is this long
+const DEFAULT_FRAMES = 600
+
+// headless runs the simulation without the visualizer, so it builds and runs without harfang and a display.
+// It takes the same flags and config file as the main program, -headless is implied
+func main() {
+	cfg, _, err := config.Parse(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+
+	if cfg.Frames <= 0 && cfg.Duration <= 0 {
+		cfg.Frames = DEFAULT_FRAMES
+	}
+	algorithm, secondaryAlgorithm, resolveAlgorithm, integrator := cfg.Apply()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	options := headless.Options{Objects: cfg.Objects, Frames: cfg.Frames, Duration: time.Duration(cfg.Duration)}
+	if _, err := headless.Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, options, ctx); err != nil {
+		log.Fatalf("Headless simulation failed: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package bench
+
+import (
+	"BachelorThesis/engine/config"
+	"BachelorThesis/engine/headless"
+	"BachelorThesis/engine/profiling"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Options of a benchmark, every combination runs on every rung of the ladder Trials times
+type Options struct {
+	// object counts, the thesis experiment doubled them from 1024 to 32768
+	Ladder []int
+	// frames before the measured ones, caches and the contact persistence settle during them
+	Warmup int
+	Frames int
+	Trials int
+	// every trial starts from the same scene
+	Seed int64
+	// a combination whose frame is slower than that on average skips the larger rungs, 0 for no limit
+	MaxFrame time.Duration
+}
+
+// Record is one trial of one combination, times are milliseconds per frame
+type Record struct {
+	Broad          string `json:"broad"`
+	BroadVariant   string `json:"broad_variant"`
+	Narrow         string `json:"narrow"`
+	NarrowVariant  string `json:"narrow_variant"`
+	Resolve        string `json:"resolve"`
+	ResolveVariant string `json:"resolve_variant"`
+	Pipeline       string `json:"pipeline"`
+
+	Objects int `json:"objects"`
+	Trial   int `json:"trial"`
+	Frames  int `json:"frames"`
+
+	FrameMs   float64 `json:"frame_ms"`
+	SortMs    float64 `json:"sort_ms"`
+	SweepMs   float64 `json:"sweep_ms"`
+	NarrowMs  float64 `json:"narrow_ms"`
+	ResolveMs float64 `json:"resolve_ms"`
+	FPS       float64 `json:"fps"`
+}
+
+// Combinations crosses the variants of the broad and the narrow phase, every variant of the resolvers
+// and the pipelines. The base config gives the algorithms and everything else
+func Combinations(base config.Config, broadVariants, narrowVariants, resolvers, pipelines []string) ([]config.Config, error) {
+	combinations := make([]config.Config, 0)
+	for _, broadVariant := range broadVariants {
+		for _, narrowVariant := range narrowVariants {
+			for _, resolve := range resolvers {
+				resolveVariants := config.ResolveVariants(resolve)
+				if resolveVariants == nil {
+					return nil, fmt.Errorf("unknown resolver %q", resolve)
+				}
+
+				for _, resolveVariant := range resolveVariants {
+					for _, pipeline := range pipelines {
+						cfg := base
+						cfg.BroadVariant = broadVariant
+						cfg.NarrowVariant = narrowVariant
+						cfg.Resolve = resolve
+						cfg.ResolveVariant = resolveVariant
+						cfg.Pipeline = pipeline
+						if err := cfg.Validate(); err != nil {
+							return nil, err
+						}
+						combinations = append(combinations, cfg)
+					}
+				}
+			}
+		}
+	}
+	return combinations, nil
+}
+
+// Run measures every combination headlessly. When ctx is done it returns the records of the finished trials
+func Run(combinations []config.Config, options Options, ctx context.Context) ([]Record, error) {
+	if options.Frames <= 0 || options.Trials <= 0 {
+		return nil, fmt.Errorf("benchmark needs frames and trials")
+	}
+
+	records := make([]Record, 0, len(combinations)*len(options.Ladder)*options.Trials)
+	for i, cfg := range combinations {
+		log.Printf("Benchmark combination %d of %d", i+1, len(combinations))
+
+	ladder:
+		for _, objects := range options.Ladder {
+			cfg.Objects = objects
+			cfg.Seed = options.Seed
+
+			for trial := 0; trial < options.Trials; trial++ {
+				algorithm, secondaryAlgorithm, resolveAlgorithm, integrator := cfg.Apply()
+				runOptions := headless.Options{Objects: objects, Frames: options.Frames, Warmup: options.Warmup, Profile: true}
+
+				result, err := headless.Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, runOptions, ctx)
+				if err != nil {
+					return records, err
+				}
+				if ctx.Err() != nil {
+					return records, ctx.Err()
+				}
+
+				record := newRecord(cfg, trial, result)
+				records = append(records, record)
+
+				if options.MaxFrame > 0 && record.FrameMs > milliseconds(options.MaxFrame) {
+					log.Printf("Frame takes %.1f ms with %d objects, the larger counts are skipped", record.FrameMs, objects)
+					break ladder
+				}
+			}
+		}
+	}
+	return records, nil
+}
+
+func newRecord(cfg config.Config, trial int, result headless.Result) Record {
+	frames := float64(result.Frames)
+	perFrame := func(d time.Duration) float64 {
+		return milliseconds(d) / frames
+	}
+
+	return Record{
+		Broad:          cfg.Broad,
+		BroadVariant:   cfg.BroadVariant,
+		Narrow:         cfg.Narrow,
+		NarrowVariant:  cfg.NarrowVariant,
+		Resolve:        cfg.Resolve,
+		ResolveVariant: cfg.ResolveVariant,
+		Pipeline:       cfg.Pipeline,
+
+		Objects: result.Objects,
+		Trial:   trial,
+		Frames:  result.Frames,
+
+		FrameMs:   perFrame(result.Elapsed),
+		SortMs:    perFrame(result.Stages[profiling.SORT]),
+		SweepMs:   perFrame(result.Stages[profiling.SWEEP]),
+		NarrowMs:  perFrame(result.Stages[profiling.NARROW]),
+		ResolveMs: perFrame(result.Stages[profiling.RESOLVE]),
+		FPS:       result.FPS(),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+var csvHeader = []string{
+	"broad", "broad_variant", "narrow", "narrow_variant", "resolve", "resolve_variant", "pipeline",
+	"objects", "trial", "frames", "frame_ms", "sort_ms", "sweep_ms", "narrow_ms", "resolve_ms", "fps",
+}
+
+func WriteCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	number := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 4, 64)
+	}
+	for _, r := range records {
+		row := []string{
+			r.Broad, r.BroadVariant, r.Narrow, r.NarrowVariant, r.Resolve, r.ResolveVariant, r.Pipeline,
+			strconv.Itoa(r.Objects), strconv.Itoa(r.Trial), strconv.Itoa(r.Frames),
+			number(r.FrameMs), number(r.SortMs), number(r.SweepMs), number(r.NarrowMs), number(r.ResolveMs), number(r.FPS),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteJSON(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
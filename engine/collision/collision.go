@@ -1,19 +1,93 @@
 package collision
 
 import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/detection"
+	bvh "BachelorThesis/engine/collision/detection/BVH"
+	sat "BachelorThesis/engine/collision/detection/SAT"
 	"BachelorThesis/engine/collision/detection/SaP"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/collision/verify"
 	"BachelorThesis/engine/constants"
 	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/profiling"
 	"log"
 )
 
 func ProcessCollisions(objects *[]objects.Object, algorithm, secondaryAlgorithm, resolveAlgorithm string) {
+	// static planes are infinite, so they are kept out of the broad phase
+	bounded := (*objects)[:partitionPlanes(*objects)]
+
+	// manifolds of the pairs that stopped touching are forgotten
+	contact.NextFrame()
+
+	// the broad phases are checked before the real one, their pairs are only collected
+	verify.Step(&bounded)
+
 	switch algorithm {
 	case constants.SaP:
-		SaP.Collision(objects, secondaryAlgorithm, resolveAlgorithm)
+		SaP.Collision(&bounded, secondaryAlgorithm, resolveAlgorithm)
+	case constants.BVH:
+		bvh.Collision(&bounded, secondaryAlgorithm, resolveAlgorithm)
 	case constants.NoAlgo:
 		return
 	default:
 		log.Panicf("Unknown algorithm: %s", algorithm)
 	}
+
+	narrowStart := profiling.Start()
+	detection.ResolveImpacts(&bounded, secondaryAlgorithm, resolveAlgorithm)
+	processPlanes(objects, len(bounded), resolveAlgorithm)
+	profiling.Stop(profiling.NARROW, narrowStart)
+
+	resolveStart := profiling.Start()
+	resolving.Solve(objects, resolveAlgorithm)
+	profiling.Stop(profiling.RESOLVE, resolveStart)
+}
+
+// partitionPlanes moves the planes to the end of the pool and returns the number of other objects
+func partitionPlanes(pool []objects.Object) int {
+	end := len(pool)
+	for i := 0; i < end; {
+		if _, ok := pool[i].(*objects.Plane); ok {
+			end--
+			pool[i], pool[end] = pool[end], pool[i]
+		} else {
+			i++
+		}
+	}
+	return end
+}
+
+// processPlanes tests every plane against every object whose bounding box reaches behind it
+func processPlanes(pool *[]objects.Object, boundedCount int, resolveAlgorithm string) {
+	for p := boundedCount; p < len(*pool); p++ {
+		plane := (*pool)[p].(*objects.Plane)
+		normal := plane.GetNormal()
+		offset := plane.GetOffset()
+
+		for i := 0; i < boundedCount; i++ {
+			bb, err := (*pool)[i].GetBoundingBox()
+			if err != nil {
+				log.Printf("Warning: failed to get bounding box for object %s: %v", (*pool)[i].GetId(), err)
+				continue
+			}
+
+			// the corner of the box that is the deepest along the plane normal
+			deepest := *bb.Max
+			if normal.X > 0 {
+				deepest.X = bb.Min.X
+			}
+			if normal.Y > 0 {
+				deepest.Y = bb.Min.Y
+			}
+			if normal.Z > 0 {
+				deepest.Z = bb.Min.Z
+			}
+
+			if normal.Dot(deepest) <= offset {
+				sat.SATPlane(i, p, pool, resolveAlgorithm)
+			}
+		}
+	}
 }
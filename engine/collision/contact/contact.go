@@ -0,0 +1,99 @@
+package contact
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"math"
+)
+
+// Contact is what a narrow phase found out about a colliding pair
+type Contact struct {
+	// indices of the objects in the pool
+	AID int
+	BID int
+
+	// unit normal pointing from A to B
+	Normal vector.Vector3D
+	// penetration depth along the normal, not negative
+	Depth float64
+
+	// deepest points of A inside B and of B inside A (world space)
+	PointA vector.Vector3D
+	PointB vector.Vector3D
+
+	// which features of the shapes touch, 0 if the narrow phase can't tell.
+	// Together with the ids of the objects it finds the same contact in the next frame
+	Feature int
+}
+
+// Tangents returns two unit vectors orthogonal to the normal and to each other,
+// friction impulses are applied along them
+func (c Contact) Tangents() (vector.Vector3D, vector.Vector3D) {
+	// the world axis least aligned with the normal gives the most stable cross product
+	axis := vector.Vector3D{X: 1}
+	if math.Abs(c.Normal.Y) < math.Abs(c.Normal.X) && math.Abs(c.Normal.Y) <= math.Abs(c.Normal.Z) {
+		axis = vector.Vector3D{Y: 1}
+	} else if math.Abs(c.Normal.Z) < math.Abs(c.Normal.X) {
+		axis = vector.Vector3D{Z: 1}
+	}
+
+	t1 := c.Normal.Cross(axis).Normalize()
+	t2 := c.Normal.Cross(*t1)
+
+	return *t1, *t2
+}
+
+// SphereContact builds the contact of two spheres, it is used when there is no narrow phase
+// to find one. It returns false if the spheres do not overlap
+func SphereContact(aID, bID int, objectPool *[]objects.Object) (*Contact, bool) {
+	sphereA, okA := (*objectPool)[aID].(*objects.Sphere)
+	if !okA {
+		log.Printf("Object %d (ID: %s) is not a sphere, skipping the pair", aID, (*objectPool)[aID].GetId())
+		return nil, false
+	}
+	sphereB, okB := (*objectPool)[bID].(*objects.Sphere)
+	if !okB {
+		log.Printf("Object %d (ID: %s) is not a sphere, skipping the pair", bID, (*objectPool)[bID].GetId())
+		return nil, false
+	}
+
+	posA, errA := sphereA.GetPosition()
+	if errA != nil {
+		log.Printf("Error getting position of object %s: %v", sphereA.GetId(), errA)
+		return nil, false
+	}
+	posB, errB := sphereB.GetPosition()
+	if errB != nil {
+		log.Printf("Error getting position of object %s: %v", sphereB.GetId(), errB)
+		return nil, false
+	}
+
+	radiusA := sphereA.GetRadius()
+	radiusB := sphereB.GetRadius()
+	sumRadii := radiusA + radiusB
+
+	delta := posB.Sub(*posA)
+	distanceSq := delta.LengthSq()
+	if distanceSq > sumRadii*sumRadii {
+		return nil, false
+	}
+
+	// coincident centres have no direction, any normal will do
+	distance := math.Sqrt(distanceSq)
+	normal := vector.Vector3D{X: 1}
+	if distance >= 1e-9 {
+		normal = *delta.Mul(1 / distance)
+	} else {
+		distance = 0
+	}
+
+	return &Contact{
+		AID:    aID,
+		BID:    bID,
+		Normal: normal,
+		Depth:  sumRadii - distance,
+		PointA: *posA.Add(*normal.Mul(radiusA)),
+		PointB: *posB.Sub(*normal.Mul(radiusB)),
+	}, true
+}
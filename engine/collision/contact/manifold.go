@@ -0,0 +1,125 @@
+package contact
+
+import (
+	"BachelorThesis/engine/vector"
+	"math"
+)
+
+// four points are enough for a box to rest on a face
+const MAX_MANIFOLD_POINTS = 4
+
+// Point is one point of a manifold, the normal is shared by the whole manifold
+type Point struct {
+	// deepest points of A inside B and of B inside A (world space)
+	PointA vector.Vector3D
+	PointB vector.Vector3D
+
+	// penetration depth along the normal, not negative
+	Depth float64
+
+	// which features of the shapes touch at this point, 0 if the narrow phase can't tell
+	Feature int
+}
+
+// Manifold is every contact point of a colliding pair
+type Manifold struct {
+	// indices of the objects in the pool
+	AID int
+	BID int
+
+	// unit normal pointing from A to B
+	Normal vector.Vector3D
+
+	Points []Point
+}
+
+// Manifold turns a single point contact into a manifold
+func (c *Contact) Manifold() *Manifold {
+	return &Manifold{
+		AID:    c.AID,
+		BID:    c.BID,
+		Normal: c.Normal,
+		Points: []Point{{PointA: c.PointA, PointB: c.PointB, Depth: c.Depth, Feature: c.Feature}},
+	}
+}
+
+// Contacts splits the manifold into the point contacts the resolvers work with
+func (m *Manifold) Contacts() []Contact {
+	contacts := make([]Contact, 0, len(m.Points))
+	for _, p := range m.Points {
+		contacts = append(contacts, Contact{
+			AID:     m.AID,
+			BID:     m.BID,
+			Normal:  m.Normal,
+			Depth:   p.Depth,
+			PointA:  p.PointA,
+			PointB:  p.PointB,
+			Feature: p.Feature,
+		})
+	}
+	return contacts
+}
+
+// Reduce keeps at most MAX_MANIFOLD_POINTS points: the deepest one, the one farthest from it,
+// the one that makes the largest triangle with them and the one that adds the most area to it
+func (m *Manifold) Reduce() {
+	if len(m.Points) <= MAX_MANIFOLD_POINTS {
+		return
+	}
+
+	points := m.Points
+	// distances are measured in the contact plane
+	flat := func(i int) vector.Vector3D {
+		p := points[i].PointA
+		return *p.Sub(*m.Normal.Mul(p.Dot(m.Normal)))
+	}
+
+	first := 0
+	for i := range points {
+		if points[i].Depth > points[first].Depth {
+			first = i
+		}
+	}
+
+	second := pickPoint(len(points), func(i int) float64 {
+		return flat(i).Sub(flat(first)).LengthSq()
+	})
+
+	// signed area of the triangle (a, b, i) around the normal
+	area := func(a, b, i int) float64 {
+		edge := flat(b).Sub(flat(a))
+		return edge.Cross(*flat(i).Sub(flat(a))).Dot(m.Normal)
+	}
+
+	third := pickPoint(len(points), func(i int) float64 {
+		return math.Abs(area(first, second, i))
+	})
+
+	// the fourth point has to lie outside the triangle, so it is on the negative side of one of
+	// its edges when the triangle is oriented around the normal
+	if area(first, second, third) < 0 {
+		second, third = third, second
+	}
+	fourth := pickPoint(len(points), func(i int) float64 {
+		return -math.Min(area(first, second, i), math.Min(area(second, third, i), area(third, first, i)))
+	})
+
+	reduced := []Point{points[first], points[second], points[third]}
+	if fourth != first && fourth != second && fourth != third {
+		reduced = append(reduced, points[fourth])
+	}
+	m.Points = reduced
+}
+
+// pickPoint returns the index with the largest score
+func pickPoint(count int, score func(int) float64) int {
+	best := 0
+	bestScore := math.Inf(-1)
+	for i := 0; i < count; i++ {
+		if s := score(i); s > bestScore {
+			best = i
+			bestScore = s
+		}
+	}
+	return best
+}
@@ -0,0 +1,205 @@
+package contact
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"sync"
+)
+
+const (
+	// a point from the previous frame is dropped when its bodies slid that far apart along the contact
+	PERSISTENT_THRESHOLD = 0.02
+	// a new point closer than that to a kept one replaces it
+	MERGE_DISTANCE = 0.02
+)
+
+// pairKey uses the ids of the objects, the indices in the pool change when the pool is reordered.
+// The ids are sorted, so a pair is found whichever object the narrow phase calls A
+type pairKey struct {
+	idA string
+	idB string
+}
+
+// persistentPoint is a manifold point in the local space of its bodies, so it follows them between frames
+type persistentPoint struct {
+	localA  vector.Vector3D
+	localB  vector.Vector3D
+	feature int
+}
+
+type persistentManifold struct {
+	points []persistentPoint
+	// the frame the pair was last seen in
+	frame int
+	// features the narrow phase can't name get their own ids, counting down from -1
+	nextFeature int
+}
+
+var (
+	persistent   = make(map[pairKey]*persistentManifold)
+	persistentMu sync.Mutex
+	frame        int
+)
+
+// NextFrame forgets the pairs that did not touch during the frame that ended, it is called before the broad phase
+func NextFrame() {
+	persistentMu.Lock()
+	defer persistentMu.Unlock()
+
+	for key, pm := range persistent {
+		if pm.frame != frame {
+			delete(persistent, key)
+		}
+	}
+	frame++
+}
+
+// Persist merges the manifold with the points of the pair kept from the previous frame and reduces it.
+// Old points still touching and not replaced by new ones stay in the manifold, new points that
+// match an old one keep its feature, so warm starting finds them again
+func Persist(m *Manifold, objectPool *[]objects.Object) {
+	objA := (*objectPool)[m.AID]
+	objB := (*objectPool)[m.BID]
+
+	posA, errA := objA.GetPosition()
+	if errA != nil {
+		log.Printf("Error getting position of object %s: %v", objA.GetId(), errA)
+		return
+	}
+	posB, errB := objB.GetPosition()
+	if errB != nil {
+		log.Printf("Error getting position of object %s: %v", objB.GetId(), errB)
+		return
+	}
+	orientationA := objA.GetOrientation()
+	orientationB := objB.GetOrientation()
+
+	key := pairKey{objA.GetId(), objB.GetId()}
+	swapped := key.idA > key.idB
+	if swapped {
+		key = pairKey{key.idB, key.idA}
+	}
+
+	persistentMu.Lock()
+	defer persistentMu.Unlock()
+
+	pm, ok := persistent[key]
+	if !ok {
+		pm = &persistentManifold{nextFeature: -1}
+		persistent[key] = pm
+	}
+	pm.frame = frame
+
+	toWorld := func(pos *vector.Vector3D, orientation vector.Matrix3D, local vector.Vector3D) vector.Vector3D {
+		return *pos.Add(*orientation.MulVector(local))
+	}
+	toLocal := func(pos *vector.Vector3D, orientation vector.Matrix3D, world vector.Vector3D) vector.Vector3D {
+		return *orientation.Transpose().MulVector(*world.Sub(*pos))
+	}
+
+	// a feature names a single point, the repeated ones get their own ids below
+	for i := range m.Points {
+		for j := 0; j < i && m.Points[i].Feature != 0; j++ {
+			if m.Points[j].Feature == m.Points[i].Feature {
+				m.Points[i].Feature = 0
+			}
+		}
+	}
+
+	// the old points in world space, matched to the new points by feature first and by place only then,
+	// so a new point near an old one never takes a feature another new point still has
+	type oldPoint struct {
+		pointA, pointB vector.Vector3D
+		feature        int
+		matched        bool
+	}
+	olds := make([]oldPoint, len(pm.points))
+	for k, old := range pm.points {
+		localA, localB := old.localA, old.localB
+		if swapped {
+			localA, localB = localB, localA
+		}
+		olds[k] = oldPoint{
+			pointA:  toWorld(posA, orientationA, localA),
+			pointB:  toWorld(posB, orientationB, localB),
+			feature: old.feature,
+		}
+	}
+
+	used := make([]bool, len(m.Points))
+	holds := func(feature int) bool {
+		for i := range m.Points {
+			if m.Points[i].Feature == feature {
+				return true
+			}
+		}
+		return false
+	}
+
+	for k := range olds {
+		for i := range m.Points {
+			if !used[i] && olds[k].feature != 0 && m.Points[i].Feature == olds[k].feature {
+				used[i] = true
+				olds[k].matched = true
+				break
+			}
+		}
+	}
+
+	for k := range olds {
+		old := &olds[k]
+		if old.matched {
+			continue
+		}
+		for i := range m.Points {
+			if used[i] || m.Points[i].PointA.Sub(old.pointA).LengthSq() >= MERGE_DISTANCE*MERGE_DISTANCE {
+				continue
+			}
+			used[i] = true
+			old.matched = true
+			if !holds(old.feature) {
+				m.Points[i].Feature = old.feature
+			}
+			break
+		}
+	}
+
+	for _, old := range olds {
+		if old.matched || holds(old.feature) {
+			continue
+		}
+
+		// the old point is kept while the bodies still press it and did not slide away from it
+		separation := old.pointA.Sub(old.pointB)
+		depth := separation.Dot(m.Normal)
+		drift := separation.Sub(*m.Normal.Mul(depth))
+		if depth < 0 || drift.LengthSq() > PERSISTENT_THRESHOLD*PERSISTENT_THRESHOLD {
+			continue
+		}
+
+		m.Points = append(m.Points, Point{PointA: old.pointA, PointB: old.pointB, Depth: depth, Feature: old.feature})
+	}
+
+	for i := range m.Points {
+		if m.Points[i].Feature == 0 {
+			m.Points[i].Feature = pm.nextFeature
+			pm.nextFeature--
+		}
+	}
+
+	m.Reduce()
+
+	pm.points = pm.points[:0]
+	for _, p := range m.Points {
+		point := persistentPoint{
+			localA:  toLocal(posA, orientationA, p.PointA),
+			localB:  toLocal(posB, orientationB, p.PointB),
+			feature: p.Feature,
+		}
+		if swapped {
+			point.localA, point.localB = point.localB, point.localA
+		}
+		pm.points = append(pm.points, point)
+	}
+}
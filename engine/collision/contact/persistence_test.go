@@ -0,0 +1,72 @@
+package contact
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"testing"
+)
+
+func TestPersistMatching(t *testing.T) {
+	kept := vector.Vector3D{X: 0.5, Y: 1, Z: 0.5}
+	other := vector.Vector3D{X: -0.5, Y: 1, Z: -0.5}
+
+	// the previous frame had a single point at kept with feature 5, the cases list the new points
+	// and the features they should end with
+	cases := []struct {
+		name     string
+		points   []Point
+		features []int
+	}{
+		{
+			// the new point in the place of the old one must not take feature 5 while the other one holds it
+			name: "feature before place",
+			points: []Point{
+				{PointA: kept, PointB: kept, Feature: 7},
+				{PointA: other, PointB: other, Feature: 5},
+			},
+			features: []int{7, 5},
+		},
+		{
+			name: "place without a feature",
+			points: []Point{
+				{PointA: kept, PointB: kept},
+			},
+			features: []int{5},
+		},
+		{
+			name: "place with another feature",
+			points: []Point{
+				{PointA: kept, PointB: kept, Feature: 7},
+			},
+			features: []int{5},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := []objects.Object{
+				objectstest.Box(tc.name+"_floor", vector.Vector3D{}, vector.Vector3D{X: 1, Y: 1, Z: 1}),
+				objectstest.Box(tc.name+"_box", vector.Vector3D{Y: 2}, vector.Vector3D{X: 1, Y: 1, Z: 1}),
+			}
+			normal := vector.Vector3D{Y: 1}
+
+			Persist(&Manifold{AID: 0, BID: 1, Normal: normal, Points: []Point{{PointA: kept, PointB: kept, Feature: 5}}}, &pool)
+			NextFrame()
+
+			m := Manifold{AID: 0, BID: 1, Normal: normal, Points: tc.points}
+			Persist(&m, &pool)
+			NextFrame()
+
+			// the old point is matched, so it is not kept next to the new ones
+			if len(m.Points) != len(tc.features) {
+				t.Fatalf("%d points, want %d", len(m.Points), len(tc.features))
+			}
+			for i, feature := range tc.features {
+				if m.Points[i].Feature != feature {
+					t.Errorf("point %d has feature %d, want %d", i, m.Points[i].Feature, feature)
+				}
+			}
+		})
+	}
+}
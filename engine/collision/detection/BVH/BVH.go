@@ -0,0 +1,175 @@
+package bvh
+
+import (
+	"BachelorThesis/engine/collision/detection"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/profiling"
+	"log"
+	"runtime"
+	"sync"
+)
+
+type intPair struct {
+	a int
+	b int
+}
+
+// the tree lives between frames, objects are matched by their ids
+var dynamicTree = newTree()
+
+// Collision keeps the swept bounding boxes in the tree, so a CCD body meets everything
+// it passed during the frame. For other bodies it is their usual bounding box
+func Collision(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
+	// First step: bring the tree up to date with the pool
+	syncStart := profiling.Start()
+	syncTree(*objectPool)
+	profiling.Stop(profiling.SORT, syncStart)
+
+	// Second step: query the tree for every object
+	switch constants.AlgoType {
+	case constants.N:
+		bvhNoParallel(objectPool, secondaryAlgorithm, resolveAlgorithm)
+	case constants.PT:
+		bvhTrivialParallel(objectPool, secondaryAlgorithm, resolveAlgorithm)
+	default:
+		log.Panicf("Unknown algorithm type: %s", constants.AlgoType)
+	}
+}
+
+func syncTree(objectPool []objects.Object) {
+	dynamicTree.stamp++
+
+	for i, obj := range objectPool {
+		bb, err := obj.GetSweptBoundingBox()
+		if err != nil {
+			log.Printf("Warning: failed to get bounding box for object %s: %v", obj.GetId(), err)
+			continue
+		}
+
+		if !dynamicTree.Refit(obj.GetId(), i, fromBoundingBox(bb)) {
+			dynamicTree.Insert(obj.GetId(), i, fromBoundingBox(bb))
+		}
+	}
+
+	// objects that are not in the pool anymore
+	stale := make([]string, 0)
+	for id, leaf := range dynamicTree.leaves {
+		if dynamicTree.nodes[leaf].stamp != dynamicTree.stamp {
+			stale = append(stale, id)
+		}
+	}
+	for _, id := range stale {
+		dynamicTree.Remove(id)
+	}
+}
+
+// --- No parallel algorithm ---
+
+func bvhNoParallel(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
+	if len(*objectPool) <= 1 {
+		return
+	}
+	sweepStart := profiling.Start()
+
+	pairs := make([]intPair, 0)
+
+	for a := range *objectPool {
+		queryObject(a, *objectPool, func(pair intPair) {
+			if constants.Pipeline == constants.ParallelPipeline {
+				detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
+			} else {
+				pairs = append(pairs, pair)
+			}
+		})
+	}
+	profiling.Stop(profiling.SWEEP, sweepStart)
+
+	if constants.Pipeline == constants.ParallelPipeline {
+		return
+	}
+
+	narrowStart := profiling.Start()
+	for _, pair := range pairs {
+		detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
+	}
+	profiling.Stop(profiling.NARROW, narrowStart)
+}
+
+// --- Parallel Trivial algorithm ---
+
+func bvhTrivialParallel(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
+	if len(*objectPool) <= 1 {
+		return
+	}
+	sweepStart := profiling.Start()
+
+	workersCount := runtime.NumCPU()
+	n := len(*objectPool)
+	chunkSize := (n + workersCount - 1) / workersCount
+
+	workerPairs := make([][]intPair, workersCount)
+	wg := new(sync.WaitGroup)
+
+	// the tree is only read here, so the queries can run concurrently
+	for w := 0; w < workersCount; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			start := w * chunkSize
+			end := min(start+chunkSize, n)
+
+			for a := start; a < end; a++ {
+				queryObject(a, *objectPool, func(pair intPair) {
+					if constants.Pipeline == constants.ParallelPipeline {
+						detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
+					} else {
+						workerPairs[w] = append(workerPairs[w], pair)
+					}
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+	profiling.Stop(profiling.SWEEP, sweepStart)
+
+	if constants.Pipeline == constants.ParallelPipeline {
+		return
+	}
+
+	narrowStart := profiling.Start()
+	for _, pairs := range workerPairs {
+		for _, pair := range pairs {
+			detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
+		}
+	}
+	profiling.Stop(profiling.NARROW, narrowStart)
+}
+
+// --- Helper function ---
+
+// queryObject reports every pair (a, b) with b > a whose swept boxes overlap,
+// so each pair is found only once
+func queryObject(a int, objectPool []objects.Object, found func(intPair)) {
+	bb, err := objectPool[a].GetSweptBoundingBox()
+	if err != nil {
+		return
+	}
+	box := fromBoundingBox(bb)
+
+	dynamicTree.Query(box, func(leaf *node) {
+		b := leaf.index
+		if b <= a || b >= len(objectPool) {
+			return
+		}
+
+		otherBB, err := objectPool[b].GetSweptBoundingBox()
+		if err != nil {
+			return
+		}
+
+		if box.overlaps(fromBoundingBox(otherBB)) {
+			found(intPair{a: a, b: b})
+		}
+	})
+}
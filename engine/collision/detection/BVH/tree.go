@@ -0,0 +1,352 @@
+package bvh
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+)
+
+const (
+	// how much leaf boxes are enlarged, so slow objects do not need reinsertion every frame
+	FAT_MARGIN = 0.1
+
+	nullNode = -1
+)
+
+type aabb struct {
+	min vector.Vector3D
+	max vector.Vector3D
+}
+
+func fromBoundingBox(bb *objects.BoundingBox) aabb {
+	return aabb{min: *bb.Min, max: *bb.Max}
+}
+
+func (a aabb) fatten(margin float64) aabb {
+	return aabb{min: *a.min.AddFloat(-margin), max: *a.max.AddFloat(margin)}
+}
+
+func (a aabb) union(b aabb) aabb {
+	return aabb{
+		min: vector.Vector3D{X: min(a.min.X, b.min.X), Y: min(a.min.Y, b.min.Y), Z: min(a.min.Z, b.min.Z)},
+		max: vector.Vector3D{X: max(a.max.X, b.max.X), Y: max(a.max.Y, b.max.Y), Z: max(a.max.Z, b.max.Z)},
+	}
+}
+
+func (a aabb) surfaceArea() float64 {
+	d := a.max.Sub(a.min)
+	return 2 * (d.X*d.Y + d.Y*d.Z + d.Z*d.X)
+}
+
+func (a aabb) contains(b aabb) bool {
+	return a.min.X <= b.min.X && a.min.Y <= b.min.Y && a.min.Z <= b.min.Z &&
+		b.max.X <= a.max.X && b.max.Y <= a.max.Y && b.max.Z <= a.max.Z
+}
+
+func (a aabb) overlaps(b aabb) bool {
+	return a.min.X <= b.max.X && b.min.X <= a.max.X &&
+		a.min.Y <= b.max.Y && b.min.Y <= a.max.Y &&
+		a.min.Z <= b.max.Z && b.min.Z <= a.max.Z
+}
+
+type node struct {
+	box aabb
+
+	parent int
+	left   int
+	right  int
+
+	// -1 for free nodes, 0 for leaves
+	height int
+
+	// leaf data
+	id    string
+	index int
+	stamp uint64
+}
+
+func (n *node) isLeaf() bool {
+	return n.left == nullNode
+}
+
+// Dynamic AABB tree, leaves are kept between frames and refitted only
+// when the object leaves its fat box
+type tree struct {
+	nodes    []node
+	root     int
+	freeList int
+
+	leaves map[string]int
+	stamp  uint64
+}
+
+func newTree() *tree {
+	return &tree{
+		nodes:    make([]node, 0),
+		root:     nullNode,
+		freeList: nullNode,
+		leaves:   make(map[string]int),
+	}
+}
+
+func (t *tree) allocate() int {
+	if t.freeList == nullNode {
+		t.nodes = append(t.nodes, node{})
+		t.freeList = len(t.nodes) - 1
+		t.nodes[t.freeList].parent = nullNode
+		t.nodes[t.freeList].height = -1
+	}
+
+	n := t.freeList
+	t.freeList = t.nodes[n].parent
+	t.nodes[n] = node{parent: nullNode, left: nullNode, right: nullNode}
+	return n
+}
+
+func (t *tree) release(n int) {
+	t.nodes[n] = node{parent: t.freeList, left: nullNode, right: nullNode, height: -1}
+	t.freeList = n
+}
+
+// Insert adds a leaf for the object with a fat box around bb
+func (t *tree) Insert(id string, index int, bb aabb) {
+	leaf := t.allocate()
+	t.nodes[leaf].box = bb.fatten(FAT_MARGIN)
+	t.nodes[leaf].id = id
+	t.nodes[leaf].index = index
+	t.nodes[leaf].stamp = t.stamp
+	t.leaves[id] = leaf
+
+	t.insertLeaf(leaf)
+}
+
+// Remove deletes the leaf of the object, if there is one
+func (t *tree) Remove(id string) {
+	leaf, ok := t.leaves[id]
+	if !ok {
+		return
+	}
+
+	delete(t.leaves, id)
+	t.removeLeaf(leaf)
+	t.release(leaf)
+}
+
+// Refit updates the leaf of the object, reinserting it only if the tight box
+// is not inside the fat one anymore. Returns false if the object is not in the tree
+func (t *tree) Refit(id string, index int, bb aabb) bool {
+	leaf, ok := t.leaves[id]
+	if !ok {
+		return false
+	}
+
+	t.nodes[leaf].index = index
+	t.nodes[leaf].stamp = t.stamp
+
+	if t.nodes[leaf].box.contains(bb) {
+		return true
+	}
+
+	t.removeLeaf(leaf)
+	t.nodes[leaf].box = bb.fatten(FAT_MARGIN)
+	t.insertLeaf(leaf)
+	return true
+}
+
+// Query calls found for every leaf whose fat box overlaps bb
+func (t *tree) Query(bb aabb, found func(leaf *node)) {
+	if t.root == nullNode {
+		return
+	}
+
+	stack := make([]int, 0, 64)
+	stack = append(stack, t.root)
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if !t.nodes[n].box.overlaps(bb) {
+			continue
+		}
+
+		if t.nodes[n].isLeaf() {
+			found(&t.nodes[n])
+		} else {
+			stack = append(stack, t.nodes[n].left, t.nodes[n].right)
+		}
+	}
+}
+
+func (t *tree) insertLeaf(leaf int) {
+	if t.root == nullNode {
+		t.root = leaf
+		t.nodes[leaf].parent = nullNode
+		return
+	}
+
+	// find the best sibling using the surface area heuristic
+	leafBox := t.nodes[leaf].box
+	index := t.root
+	for !t.nodes[index].isLeaf() {
+		left := t.nodes[index].left
+		right := t.nodes[index].right
+
+		area := t.nodes[index].box.surfaceArea()
+		combinedArea := t.nodes[index].box.union(leafBox).surfaceArea()
+
+		// cost of creating a new parent for this node and the new leaf
+		cost := 2 * combinedArea
+		// minimum cost of pushing the leaf further down the tree
+		inheritanceCost := 2 * (combinedArea - area)
+
+		costLeft := t.descendCost(left, leafBox) + inheritanceCost
+		costRight := t.descendCost(right, leafBox) + inheritanceCost
+
+		if cost < costLeft && cost < costRight {
+			break
+		}
+
+		if costLeft < costRight {
+			index = left
+		} else {
+			index = right
+		}
+	}
+
+	sibling := index
+	oldParent := t.nodes[sibling].parent
+	newParent := t.allocate()
+	t.nodes[newParent].parent = oldParent
+	t.nodes[newParent].box = leafBox.union(t.nodes[sibling].box)
+	t.nodes[newParent].height = t.nodes[sibling].height + 1
+	t.nodes[newParent].left = sibling
+	t.nodes[newParent].right = leaf
+	t.nodes[sibling].parent = newParent
+	t.nodes[leaf].parent = newParent
+
+	if oldParent == nullNode {
+		t.root = newParent
+	} else if t.nodes[oldParent].left == sibling {
+		t.nodes[oldParent].left = newParent
+	} else {
+		t.nodes[oldParent].right = newParent
+	}
+
+	t.refitAncestors(newParent)
+}
+
+func (t *tree) descendCost(n int, leafBox aabb) float64 {
+	combined := leafBox.union(t.nodes[n].box).surfaceArea()
+	if t.nodes[n].isLeaf() {
+		return combined
+	}
+	return combined - t.nodes[n].box.surfaceArea()
+}
+
+func (t *tree) removeLeaf(leaf int) {
+	if leaf == t.root {
+		t.root = nullNode
+		return
+	}
+
+	parent := t.nodes[leaf].parent
+	grandParent := t.nodes[parent].parent
+	sibling := t.nodes[parent].left
+	if sibling == leaf {
+		sibling = t.nodes[parent].right
+	}
+
+	if grandParent == nullNode {
+		t.root = sibling
+		t.nodes[sibling].parent = nullNode
+		t.release(parent)
+		return
+	}
+
+	if t.nodes[grandParent].left == parent {
+		t.nodes[grandParent].left = sibling
+	} else {
+		t.nodes[grandParent].right = sibling
+	}
+	t.nodes[sibling].parent = grandParent
+	t.release(parent)
+
+	t.refitAncestors(grandParent)
+}
+
+// walks from n to the root fixing boxes and heights, rebalancing on the way
+func (t *tree) refitAncestors(n int) {
+	for n != nullNode {
+		n = t.balance(n)
+
+		left := t.nodes[n].left
+		right := t.nodes[n].right
+
+		t.nodes[n].height = 1 + max(t.nodes[left].height, t.nodes[right].height)
+		t.nodes[n].box = t.nodes[left].box.union(t.nodes[right].box)
+
+		n = t.nodes[n].parent
+	}
+}
+
+// rotates the subtree at a if it is imbalanced, returns the new subtree root
+func (t *tree) balance(a int) int {
+	if t.nodes[a].isLeaf() || t.nodes[a].height < 2 {
+		return a
+	}
+
+	b := t.nodes[a].left
+	c := t.nodes[a].right
+
+	diff := t.nodes[c].height - t.nodes[b].height
+	if diff > 1 {
+		return t.rotate(a, c, b, false)
+	}
+	if diff < -1 {
+		return t.rotate(a, b, c, true)
+	}
+	return a
+}
+
+// lifts the taller child up over a. other is the shorter child of a,
+// heavyIsLeft tells on which side of a the taller child was
+func (t *tree) rotate(a, heavy, other int, heavyIsLeft bool) int {
+	f := t.nodes[heavy].left
+	g := t.nodes[heavy].right
+
+	// heavy takes a's place
+	t.nodes[heavy].left = a
+	t.nodes[heavy].parent = t.nodes[a].parent
+	t.nodes[a].parent = heavy
+
+	parent := t.nodes[heavy].parent
+	if parent == nullNode {
+		t.root = heavy
+	} else if t.nodes[parent].left == a {
+		t.nodes[parent].left = heavy
+	} else {
+		t.nodes[parent].right = heavy
+	}
+
+	// the taller grandchild stays under heavy, the shorter one goes to a
+	keep, give := f, g
+	if t.nodes[f].height < t.nodes[g].height {
+		keep, give = g, f
+	}
+
+	t.nodes[heavy].right = keep
+	if heavyIsLeft {
+		t.nodes[a].left = give
+	} else {
+		t.nodes[a].right = give
+	}
+	t.nodes[give].parent = a
+
+	t.nodes[a].box = t.nodes[other].box.union(t.nodes[give].box)
+	t.nodes[a].height = 1 + max(t.nodes[other].height, t.nodes[give].height)
+
+	t.nodes[heavy].box = t.nodes[a].box.union(t.nodes[keep].box)
+	t.nodes[heavy].height = 1 + max(t.nodes[a].height, t.nodes[keep].height)
+
+	return heavy
+}
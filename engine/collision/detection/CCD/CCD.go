@@ -0,0 +1,218 @@
+package ccd
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	gjk "BachelorThesis/engine/collision/detection/GJK"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"math"
+	"sync"
+)
+
+const (
+	// conservative advancement stops this far from the impact, so the bodies don't overlap there
+	CCD_TOLERANCE = 0.005
+	// bodies closer than that after the rewind are in contact
+	CCD_CONTACT_DISTANCE = 4 * CCD_TOLERANCE
+	CCD_MAX_ITERATIONS   = 32
+)
+
+// Impact is the first touch of a pair during the frame, Time is the part of the frame before it (0..1)
+type Impact struct {
+	AID  int
+	BID  int
+	Time float64
+}
+
+// impacts of the current frame, the broad phase can find them in parallel
+var (
+	impacts   []Impact
+	impactsMu sync.Mutex
+)
+
+// AddImpact stores the impact until the broad phase is done, see Take
+func AddImpact(aID, bID int, time float64) {
+	impactsMu.Lock()
+	defer impactsMu.Unlock()
+
+	impacts = append(impacts, Impact{AID: aID, BID: bID, Time: time})
+}
+
+// Take returns the impacts of the frame and forgets them
+func Take() []Impact {
+	impactsMu.Lock()
+	defer impactsMu.Unlock()
+
+	taken := impacts
+	impacts = nil
+	return taken
+}
+
+// TimeOfImpact finds when the pair first touches during the step, the bodies moved in straight lines
+// from their start poses (see SaveStartPose) to where they are now.
+// It returns false if the bodies already touched at the start, the discrete test is enough then,
+// or if they don't meet during the frame
+func TimeOfImpact(a, b objects.Object) (float64, bool) {
+	moveA, errA := stepMove(a)
+	moveB, errB := stepMove(b)
+	if errA != nil || errB != nil {
+		log.Printf("CCD: failed to get the moves of %s and %s: %v, %v", a.GetId(), b.GetId(), errA, errB)
+		return 0, false
+	}
+
+	sphereA, okA := a.(*objects.Sphere)
+	sphereB, okB := b.(*objects.Sphere)
+	if okA && okB {
+		return sweptSpheres(sphereA, sphereB, moveA, moveB)
+	}
+
+	return conservativeAdvancement(a, b, moveA, moveB)
+}
+
+// stepMove is the move of the object from its start pose to its current position
+func stepMove(object objects.Object) (vector.Vector3D, error) {
+	position, err := object.GetPosition()
+	if err != nil {
+		return vector.Vector3D{}, err
+	}
+
+	start, _ := object.GetStartPose()
+	return *position.Sub(start), nil
+}
+
+// sweptSpheres solves |d + v t| = rA + rB for the centres moving along straight lines,
+// the moves are the ones of the whole step
+func sweptSpheres(a, b *objects.Sphere, moveA, moveB vector.Vector3D) (float64, bool) {
+	posA, errA := a.GetPosition()
+	posB, errB := b.GetPosition()
+	if errA != nil || errB != nil {
+		log.Printf("CCD: failed to get positions of %s and %s: %v, %v", a.GetId(), b.GetId(), errA, errB)
+		return 0, false
+	}
+
+	// relative to A, from the start of the frame
+	distance := posB.Sub(moveB).Sub(*posA.Sub(moveA))
+	relative := moveB.Sub(moveA)
+	radius := a.GetRadius() + b.GetRadius()
+
+	qa := relative.LengthSq()
+	qb := 2 * distance.Dot(*relative)
+	qc := distance.LengthSq() - radius*radius
+
+	// touching at the start or moving apart
+	if qc <= 0 || qb >= 0 {
+		return 0, false
+	}
+
+	discriminant := qb*qb - 4*qa*qc
+	if discriminant < 0 {
+		return 0, false
+	}
+
+	t := (-qb - math.Sqrt(discriminant)) / (2 * qa)
+	if t > 1 {
+		return 0, false
+	}
+
+	// stop short of the impact like conservative advancement does, touching spheres are ambiguous
+	return math.Max(0, t-CCD_TOLERANCE/2/math.Sqrt(qa)), true
+}
+
+// conservativeAdvancement moves the pair by the time the closing speed along the closest points
+// needs to cover the distance, it never steps over the impact. The orientation is taken from the
+// end of the frame, fast bodies move much more than they turn
+func conservativeAdvancement(a, b objects.Object, moveA, moveB vector.Vector3D) (float64, bool) {
+	relative := moveB.Sub(moveA)
+
+	t := 0.0
+	for i := 0; i < CCD_MAX_ITERATIONS; i++ {
+		result := gjk.Query(shifted{a, *moveA.Mul(t - 1)}, shifted{b, *moveB.Mul(t - 1)})
+		if result.Intersect {
+			return t, i > 0
+		}
+		if result.Distance < CCD_TOLERANCE {
+			return t, true
+		}
+
+		normal := result.PointB.Sub(result.PointA).Mul(1 / result.Distance)
+		closing := -relative.Dot(*normal)
+		// the distance of two convex bodies moving along straight lines only falls until it starts to grow
+		if closing <= 0 {
+			return 0, false
+		}
+
+		t += (result.Distance - CCD_TOLERANCE/2) / closing
+		if t > 1 {
+			return 0, false
+		}
+	}
+
+	return t, true
+}
+
+// shifted is the object moved by offset without changing it, other pairs may use it at the same time
+type shifted struct {
+	objects.Object
+	offset vector.Vector3D
+}
+
+func (s shifted) Support(direction vector.Vector3D) *vector.Vector3D {
+	return s.Object.Support(direction).Add(s.offset)
+}
+
+func (s shifted) GetPosition() (*vector.Vector3D, error) {
+	position, err := s.Object.GetPosition()
+	if err != nil {
+		return nil, err
+	}
+	return position.Add(s.offset), nil
+}
+
+// Rewind moves every CCD body back along its move to its earliest impact of the frame, the rest of
+// its move is lost. Other bodies stay where they are
+func Rewind(impacts []Impact, objectPool *[]objects.Object) {
+	earliest := make(map[int]float64)
+	for _, impact := range impacts {
+		for _, id := range [2]int{impact.AID, impact.BID} {
+			if !(*objectPool)[id].GetCCD() {
+				continue
+			}
+			if t, ok := earliest[id]; !ok || impact.Time < t {
+				earliest[id] = impact.Time
+			}
+		}
+	}
+
+	for id, t := range earliest {
+		obj := (*objectPool)[id]
+
+		move, err := stepMove(obj)
+		if err != nil {
+			log.Printf("CCD: failed to rewind %s: %v", obj.GetId(), err)
+			continue
+		}
+
+		start, _ := obj.GetStartPose()
+		obj.SetPosition(*start.Add(*move.Mul(t)))
+		// Update without time only refreshes the bounding box, the planes are tested against it
+		obj.Update(0)
+	}
+}
+
+// ImpactContact builds the contact of a rewound pair from its closest points.
+// It returns false if the bodies overlap, the discrete test finds that contact, or if they are apart
+func ImpactContact(aID, bID int, objectPool *[]objects.Object) (*contact.Contact, bool) {
+	result := gjk.Query((*objectPool)[aID], (*objectPool)[bID])
+	if result.Intersect || result.Distance > CCD_CONTACT_DISTANCE || result.Distance == 0 {
+		return nil, false
+	}
+
+	return &contact.Contact{
+		AID:    aID,
+		BID:    bID,
+		Normal: *result.PointB.Sub(result.PointA).Mul(1 / result.Distance),
+		PointA: result.PointA,
+		PointB: result.PointB,
+	}, true
+}
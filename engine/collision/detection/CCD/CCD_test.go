@@ -0,0 +1,137 @@
+package ccd
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+// bullet is a CCD sphere that moved from start to end during the step. Its velocity is left at zero,
+// CCD follows the poses and not the velocity, which the resolver may have changed already
+func bullet(start, end vector.Vector3D, radius float64) *objects.Sphere {
+	sphere := objectstest.Sphere("bullet", start, radius)
+	sphere.SetPosition(end)
+	sphere.Update(0)
+	sphere.SetCCD(true)
+	return sphere
+}
+
+func TestTimeOfImpact(t *testing.T) {
+	thinWall := objectstest.Box("wall", vector.Vector3D{}, vector.Vector3D{X: 0.05, Y: 1, Z: 1})
+	thinWall.SetMass(0)
+	ball := objectstest.Sphere("ball", vector.Vector3D{}, 0.5)
+	ball.SetMass(0)
+
+	cases := []struct {
+		name   string
+		bullet *objects.Sphere
+		target objects.Object
+		hit    bool
+		// where the front of the bullet is at the impact
+		front float64
+	}{
+		{
+			// the move of 2 is twice the diameter, the end pose is already behind the wall
+			name:   "sphere through a thin box",
+			bullet: bullet(vector.Vector3D{X: -1}, vector.Vector3D{X: 1}, 0.5),
+			target: thinWall,
+			hit:    true,
+			front:  -0.05,
+		},
+		{
+			name:   "sphere through a sphere",
+			bullet: bullet(vector.Vector3D{X: -2}, vector.Vector3D{X: 2}, 0.5),
+			target: ball,
+			hit:    true,
+			front:  -0.5,
+		},
+		{
+			name:   "sphere past a box",
+			bullet: bullet(vector.Vector3D{X: -1, Y: 2}, vector.Vector3D{X: 1, Y: 2}, 0.5),
+			target: thinWall,
+		},
+		{
+			name:   "sphere stopping short of a sphere",
+			bullet: bullet(vector.Vector3D{X: -3}, vector.Vector3D{X: -1.5}, 0.5),
+			target: ball,
+		},
+		{
+			// touching at the start is left to the discrete test
+			name:   "sphere leaving a sphere",
+			bullet: bullet(vector.Vector3D{X: -0.9}, vector.Vector3D{X: -3}, 0.5),
+			target: ball,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := []objects.Object{tc.bullet, tc.target}
+			time, hit := TimeOfImpact(tc.bullet, tc.target)
+			if hit != tc.hit {
+				t.Fatalf("hit = %v at %v, want %v", hit, time, tc.hit)
+			}
+			if !hit {
+				return
+			}
+
+			Rewind([]Impact{{AID: 0, BID: 1, Time: time}}, &pool)
+
+			// the bullet stops just before the target
+			position, _ := tc.bullet.GetPosition()
+			gap := tc.front - (position.X + tc.bullet.GetRadius())
+			if gap < 0 || gap > CCD_CONTACT_DISTANCE {
+				t.Errorf("the bullet stopped %v before the target, want 0..%v", gap, CCD_CONTACT_DISTANCE)
+			}
+			if position.Y != 0 || position.Z != 0 {
+				t.Errorf("the bullet left its path: %v", *position)
+			}
+			// the box follows the rewound bullet
+			if bb, _ := tc.bullet.GetBoundingBox(); bb.Max.X != position.X+tc.bullet.GetRadius() {
+				t.Errorf("bounding box %v is not at the rewound position %v", *bb.Max, *position)
+			}
+
+			c, ok := ImpactContact(0, 1, &pool)
+			if !ok {
+				t.Fatal("no contact at the impact")
+			}
+			if c.Normal.Sub(vector.Vector3D{X: 1}).Length() > 1e-6 {
+				t.Errorf("normal %v, want +X", c.Normal)
+			}
+			if math.Abs(c.PointB.X-c.PointA.X-gap) > 1e-6 {
+				t.Errorf("contact points %v and %v are not %v apart", c.PointA, c.PointB, gap)
+			}
+		})
+	}
+}
+
+// both solutions of the impact meet: the exact one for spheres and the conservative advancement
+func TestSweptSpheresMatchesAdvancement(t *testing.T) {
+	a := bullet(vector.Vector3D{X: -2, Y: 0.3}, vector.Vector3D{X: 2, Y: 0.3}, 0.5)
+	b := objectstest.Sphere("ball", vector.Vector3D{}, 0.5)
+	move := vector.Vector3D{X: 4}
+
+	exact, okExact := sweptSpheres(a, b, move, vector.Vector3D{})
+	advanced, okAdvanced := conservativeAdvancement(a, b, move, vector.Vector3D{})
+	if !okExact || !okAdvanced {
+		t.Fatalf("hits: exact %v, advancement %v", okExact, okAdvanced)
+	}
+	// both stop within the tolerance before the impact
+	if math.Abs(exact-advanced)*move.Length() > CCD_TOLERANCE {
+		t.Errorf("exact impact at %v, advancement at %v", exact, advanced)
+	}
+}
+
+// ImpactContact leaves overlapping and distant pairs to the discrete test
+func TestImpactContactApart(t *testing.T) {
+	for name, x := range map[string]float64{"overlapping": 0.9, "apart": 1.5} {
+		pool := []objects.Object{
+			objectstest.Sphere("a", vector.Vector3D{}, 0.5),
+			objectstest.Sphere("b", vector.Vector3D{X: x}, 0.5),
+		}
+		if _, ok := ImpactContact(0, 1, &pool); ok {
+			t.Errorf("%s: a contact was built", name)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package epa
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	gjk "BachelorThesis/engine/collision/detection/GJK"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+)
+
+const (
+	EPA_MAX_ITERATIONS = 128
+	EPA_TOLERANCE      = 1e-4
+)
+
+type face struct {
+	a, b, c  int
+	normal   vector.Vector3D
+	distance float64
+}
+
+type edge struct {
+	a, b int
+}
+
+// EPANoParallel runs GJK on the pair and, if the objects intersect, expands
+// the GJK simplex to get the contact for the resolver
+func EPANoParallel(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	objA := (*objectPool)[aID]
+	objB := (*objectPool)[bID]
+
+	result := gjk.Query(objA, objB)
+	if !result.Intersect {
+		return
+	}
+
+	c := Penetration(objA, objB, result)
+	c.AID = aID
+	c.BID = bID
+	resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
+}
+
+// Penetration expands the simplex of an intersecting GJK query to the face of
+// the Minkowski difference closest to the origin and returns the contact built from it.
+// A simplex that can't be expanded or a polytope that collapses means the shapes only touch,
+// they get a zero-depth contact along the last GJK search direction
+func Penetration(a, b objects.Object, result gjk.Result) *contact.Contact {
+	points := make([]gjk.SupportPoint, 0, EPA_MAX_ITERATIONS+4)
+	for i := 0; i < result.Simplex.Size; i++ {
+		points = append(points, result.Simplex.Points[i])
+	}
+
+	points, err := toTetrahedron(a, b, points)
+	if err != nil {
+		return touchingContact(a, b, result.Direction)
+	}
+
+	// the centroid stays inside the polytope while it grows
+	inside := points[0].P.Add(points[1].P).Add(points[2].P).Add(points[3].P).Mul(0.25)
+
+	faces := make([]face, 0, 4)
+	for _, f := range [4][3]int{{0, 1, 2}, {0, 3, 1}, {0, 2, 3}, {1, 3, 2}} {
+		if face, ok := newFace(points, *inside, f[0], f[1], f[2]); ok {
+			faces = append(faces, face)
+		}
+	}
+
+	var closest face
+	for i := 0; i < EPA_MAX_ITERATIONS; i++ {
+		// every face collapsed, there is no depth to measure
+		if len(faces) == 0 {
+			return touchingContact(a, b, result.Direction)
+		}
+
+		closest = faces[0]
+		for _, f := range faces[1:] {
+			if f.distance < closest.distance {
+				closest = f
+			}
+		}
+
+		// curved shapes never converge exactly, so the tolerance is relative
+		w := support(a, b, closest.normal)
+		if w.P.Dot(closest.normal)-closest.distance < EPA_TOLERANCE*math.Max(1, closest.distance) {
+			return buildContact(points, closest)
+		}
+
+		points = append(points, w)
+		newIndex := len(points) - 1
+
+		// remove every face the new point can see and remember the horizon
+		horizon := make([]edge, 0)
+		kept := faces[:0]
+		for _, f := range faces {
+			if f.normal.Dot(*w.P.Sub(points[f.a].P)) > 0 {
+				horizon = toggleEdge(horizon, edge{f.a, f.b})
+				horizon = toggleEdge(horizon, edge{f.b, f.c})
+				horizon = toggleEdge(horizon, edge{f.c, f.a})
+			} else {
+				kept = append(kept, f)
+			}
+		}
+		faces = kept
+
+		for _, e := range horizon {
+			if face, ok := newFace(points, *inside, e.a, e.b, newIndex); ok {
+				faces = append(faces, face)
+			}
+		}
+	}
+
+	// not converged, the best face so far is still a good guess
+	return buildContact(points, closest)
+}
+
+// touchingContact is the contact of shapes that touch along the direction: the points
+// of A and B furthest along it, without any depth
+func touchingContact(a, b objects.Object, direction vector.Vector3D) *contact.Contact {
+	normal := vector.Vector3D{X: 1}
+	if direction.LengthSq() > EPA_TOLERANCE*EPA_TOLERANCE {
+		normal = *direction.Normalize()
+	}
+
+	return &contact.Contact{
+		Normal: normal,
+		PointA: *a.Support(normal),
+		PointB: *b.Support(*normal.Negate()),
+	}
+}
+
+func support(a, b objects.Object, direction vector.Vector3D) gjk.SupportPoint {
+	pA := a.Support(direction)
+	pB := b.Support(*direction.Negate())
+	return gjk.SupportPoint{P: *pA.Sub(*pB), A: *pA, B: *pB}
+}
+
+// an edge shared by two removed faces is inside the hole, so it is dropped
+func toggleEdge(edges []edge, e edge) []edge {
+	for i, other := range edges {
+		if other.a == e.b && other.b == e.a {
+			return append(edges[:i], edges[i+1:]...)
+		}
+	}
+	return append(edges, e)
+}
+
+// newFace is not ok for a sliver triangle, its normal would be noise or zero
+func newFace(points []gjk.SupportPoint, inside vector.Vector3D, a, b, c int) (face, bool) {
+	pa := points[a].P
+	cross := points[b].P.Sub(pa).Cross(*points[c].P.Sub(pa))
+	if cross.Length() < EPA_TOLERANCE {
+		return face{}, false
+	}
+	normal := cross.Normalize()
+
+	// keep the normals pointing out of the polytope
+	if normal.Dot(*pa.Sub(inside)) < 0 {
+		b, c = c, b
+		normal = normal.Negate()
+	}
+	return face{a: a, b: b, c: c, normal: *normal, distance: normal.Dot(pa)}, true
+}
+
+// toTetrahedron grows a touching GJK simplex (point, segment or triangle)
+// to a tetrahedron that has some volume
+func toTetrahedron(a, b objects.Object, points []gjk.SupportPoint) ([]gjk.SupportPoint, error) {
+	axes := []vector.Vector3D{
+		{X: 1}, {X: -1},
+		{Y: 1}, {Y: -1},
+		{Z: 1}, {Z: -1},
+	}
+
+	if len(points) == 1 {
+		for _, axis := range axes {
+			w := support(a, b, axis)
+			if w.P.Sub(points[0].P).LengthSq() > EPA_TOLERANCE {
+				points = append(points, w)
+				break
+			}
+		}
+	}
+
+	if len(points) == 2 {
+		line := points[1].P.Sub(points[0].P)
+		for _, axis := range axes {
+			direction := line.Cross(axis)
+			if direction.LengthSq() < EPA_TOLERANCE {
+				continue
+			}
+			w := support(a, b, *direction)
+			if w.P.Sub(points[0].P).Cross(*line).LengthSq() > EPA_TOLERANCE {
+				points = append(points, w)
+				break
+			}
+		}
+	}
+
+	if len(points) == 3 {
+		normal := points[1].P.Sub(points[0].P).Cross(*points[2].P.Sub(points[0].P))
+		for _, direction := range []vector.Vector3D{*normal, *normal.Negate()} {
+			w := support(a, b, direction)
+			if math.Abs(w.P.Sub(points[0].P).Dot(*normal)) > EPA_TOLERANCE {
+				points = append(points, w)
+				break
+			}
+		}
+	}
+
+	if len(points) != 4 {
+		return nil, fmt.Errorf("simplex can not be expanded to a tetrahedron")
+	}
+	return points, nil
+}
+
+func buildContact(points []gjk.SupportPoint, f face) *contact.Contact {
+	// barycentric coordinates of the origin projection on the face
+	projection := f.normal.Mul(f.distance)
+	u, v, w := barycentric(*projection, points[f.a].P, points[f.b].P, points[f.c].P)
+
+	pointA := points[f.a].A.Mul(u).Add(*points[f.b].A.Mul(v)).Add(*points[f.c].A.Mul(w))
+	pointB := points[f.a].B.Mul(u).Add(*points[f.b].B.Mul(v)).Add(*points[f.c].B.Mul(w))
+
+	return &contact.Contact{
+		Normal: f.normal,
+		Depth:  math.Max(f.distance, 0),
+		PointA: *pointA,
+		PointB: *pointB,
+	}
+}
+
+func barycentric(p, a, b, c vector.Vector3D) (float64, float64, float64) {
+	v0 := b.Sub(a)
+	v1 := c.Sub(a)
+	v2 := p.Sub(a)
+
+	d00 := v0.Dot(*v0)
+	d01 := v0.Dot(*v1)
+	d11 := v1.Dot(*v1)
+	d20 := v2.Dot(*v0)
+	d21 := v2.Dot(*v1)
+
+	denom := d00*d11 - d01*d01
+	if math.Abs(denom) < EPA_TOLERANCE*EPA_TOLERANCE {
+		return 1, 0, 0
+	}
+
+	v := (d11*d20 - d01*d21) / denom
+	w := (d00*d21 - d01*d20) / denom
+	return 1 - v - w, v, w
+}
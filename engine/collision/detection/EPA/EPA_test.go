@@ -0,0 +1,144 @@
+package epa
+
+import (
+	gjk "BachelorThesis/engine/collision/detection/GJK"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+func TestPenetration(t *testing.T) {
+	unit := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	diagonal := *vector.Vector3D{X: 1, Y: 2, Z: -2}.Mul(1.0 / 3)
+
+	cases := []struct {
+		name   string
+		a, b   objects.Object
+		normal vector.Vector3D
+		depth  float64
+		// curved shapes converge up to the relative tolerance of EPA, polytopes exactly
+		tolerance float64
+	}{
+		{
+			name:      "spheres",
+			a:         objectstest.Sphere("a", vector.Vector3D{}, 1),
+			b:         objectstest.Sphere("b", vector.Vector3D{X: 1.5}, 1),
+			normal:    vector.Vector3D{X: 1},
+			depth:     0.5,
+			tolerance: 1e-3,
+		},
+		{
+			name:      "spheres diagonally",
+			a:         objectstest.Sphere("a", vector.Vector3D{X: 2, Y: 1, Z: -1}, 0.75),
+			b:         objectstest.Sphere("b", *vector.Vector3D{X: 2, Y: 1, Z: -1}.Add(*diagonal.Mul(1.5)), 1.25),
+			normal:    diagonal,
+			depth:     0.5,
+			tolerance: 1e-3,
+		},
+		{
+			name:      "boxes side by side",
+			a:         objectstest.Box("a", vector.Vector3D{}, unit),
+			b:         objectstest.Box("b", vector.Vector3D{X: 1.8, Y: 0.3, Z: 0.2}, unit),
+			normal:    vector.Vector3D{X: 1},
+			depth:     0.2,
+			tolerance: 1e-9,
+		},
+		{
+			name:      "box on a box",
+			a:         objectstest.Box("a", vector.Vector3D{X: 0.2, Y: 1.9, Z: -0.1}, unit),
+			b:         objectstest.Box("b", vector.Vector3D{}, vector.Vector3D{X: 3, Y: 1, Z: 3}),
+			normal:    vector.Vector3D{Y: -1},
+			depth:     0.1,
+			tolerance: 1e-9,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := gjk.Query(tc.a, tc.b)
+			if !result.Intersect {
+				t.Fatal("the shapes do not intersect")
+			}
+
+			c := Penetration(tc.a, tc.b, result)
+			if math.Abs(c.Depth-tc.depth) > tc.tolerance {
+				t.Errorf("depth = %v, want %v", c.Depth, tc.depth)
+			}
+			if c.Normal.Sub(tc.normal).Length() > math.Sqrt(tc.tolerance) {
+				t.Errorf("normal = %v, want %v", c.Normal, tc.normal)
+			}
+			// the witness points are as deep in each other as the depth
+			if gap := c.PointA.Sub(c.PointB).Dot(c.Normal); math.Abs(gap-c.Depth) > tc.tolerance {
+				t.Errorf("witness points %v and %v are %v deep along the normal, depth is %v", c.PointA, c.PointB, gap, c.Depth)
+			}
+		})
+	}
+}
+
+// flat shapes have a flat Minkowski difference, the simplex can't grow into a tetrahedron.
+// They still get a contact instead of being dropped
+func TestPenetrationTouching(t *testing.T) {
+	flat := vector.Vector3D{X: 1, Y: 1}
+	a := objectstest.Box("a", vector.Vector3D{}, flat)
+	b := objectstest.Box("b", vector.Vector3D{X: 1.5, Y: 0.5}, flat)
+
+	result := gjk.Query(a, b)
+	if !result.Intersect {
+		t.Fatal("the shapes do not intersect")
+	}
+
+	c := Penetration(a, b, result)
+	if c.Depth != 0 {
+		t.Errorf("depth = %v, want 0", c.Depth)
+	}
+	if math.Abs(c.Normal.Length()-1) > 1e-9 {
+		t.Errorf("normal %v is not a unit vector", c.Normal)
+	}
+	if c.Normal.Dot(vector.Vector3D{X: 1.5, Y: 0.5}) <= 0 {
+		t.Errorf("normal %v does not point from A to B", c.Normal)
+	}
+	if *a.Support(c.Normal) != c.PointA || *b.Support(*c.Normal.Negate()) != c.PointB {
+		t.Errorf("points %v and %v are not the supports along the normal", c.PointA, c.PointB)
+	}
+}
+
+// a face too thin to have a normal is left out of the polytope
+func TestNewFace(t *testing.T) {
+	point := func(x, y, z float64) gjk.SupportPoint {
+		return gjk.SupportPoint{P: vector.Vector3D{X: x, Y: y, Z: z}}
+	}
+	inside := vector.Vector3D{Y: -1}
+
+	cases := []struct {
+		name   string
+		points []gjk.SupportPoint
+		ok     bool
+		normal vector.Vector3D
+	}{
+		{"triangle", []gjk.SupportPoint{point(0, 0, 0), point(1, 0, 0), point(0, 0, 1)}, true, vector.Vector3D{Y: 1}},
+		{"flipped triangle", []gjk.SupportPoint{point(0, 0, 0), point(0, 0, 1), point(1, 0, 0)}, true, vector.Vector3D{Y: 1}},
+		{"collinear", []gjk.SupportPoint{point(0, 0, 0), point(1, 0, 0), point(2, 0, 0)}, false, vector.Vector3D{}},
+		{"repeated point", []gjk.SupportPoint{point(0, 0, 0), point(1, 0, 0), point(1, 0, 0)}, false, vector.Vector3D{}},
+		{"sliver", []gjk.SupportPoint{point(0, 0, 0), point(1, 0, 0), point(0.5, 0, 1e-5)}, false, vector.Vector3D{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, ok := newFace(tc.points, inside, 0, 1, 2)
+			if ok != tc.ok {
+				t.Fatalf("ok = %v, want %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if f.normal.Sub(tc.normal).Length() > 1e-9 {
+				t.Errorf("normal = %v, want %v", f.normal, tc.normal)
+			}
+			if f.distance != 0 {
+				t.Errorf("distance = %v, want 0", f.distance)
+			}
+		})
+	}
+}
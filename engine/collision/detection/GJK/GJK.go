@@ -0,0 +1,283 @@
+package gjk
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"math"
+)
+
+const (
+	GJK_MAX_ITERATIONS = 64
+	GJK_TOLERANCE      = 1e-9
+)
+
+// Point of the Minkowski difference A - B together with the points of A and B it came from
+type SupportPoint struct {
+	P vector.Vector3D
+	A vector.Vector3D
+	B vector.Vector3D
+}
+
+type Simplex struct {
+	Points [4]SupportPoint
+	Size   int
+}
+
+type Result struct {
+	Intersect bool
+
+	// only meaningful when the shapes are separated
+	Distance float64
+	PointA   vector.Vector3D
+	PointB   vector.Vector3D
+
+	// final simplex, for intersecting shapes it can be handed to EPA
+	Simplex Simplex
+	// the last search direction, it points from A towards B. Shapes that only touch have
+	// no penetration for EPA to expand, their contact normal is this direction
+	Direction vector.Vector3D
+}
+
+// Intersect is the boolean GJK query
+func Intersect(a, b objects.Object) bool {
+	return Query(a, b).Intersect
+}
+
+func support(a, b objects.Object, direction vector.Vector3D) SupportPoint {
+	pA := a.Support(direction)
+	pB := b.Support(*direction.Negate())
+	return SupportPoint{P: *pA.Sub(*pB), A: *pA, B: *pB}
+}
+
+// Query runs GJK on the Minkowski difference of a and b and returns either
+// the intersection or the distance with the closest points
+func Query(a, b objects.Object) Result {
+	posA, errA := a.GetPosition()
+	posB, errB := b.GetPosition()
+
+	direction := vector.Vector3D{X: 1, Y: 0, Z: 0}
+	if errA == nil && errB == nil {
+		if d := posB.Sub(*posA); d.LengthSq() > GJK_TOLERANCE {
+			direction = *d
+		}
+	}
+
+	simplex := Simplex{}
+	simplex.Points[0] = support(a, b, direction)
+	simplex.Size = 1
+
+	var weights [4]float64
+	var closest vector.Vector3D
+
+	for i := 0; i < GJK_MAX_ITERATIONS; i++ {
+		closest, weights = reduce(&simplex)
+
+		// the origin is inside the simplex or on its boundary
+		if simplex.Size == 4 || closest.LengthSq() < GJK_TOLERANCE {
+			return Result{Intersect: true, Simplex: simplex, Direction: direction}
+		}
+
+		direction = *closest.Negate()
+		w := support(a, b, direction)
+
+		// no progress towards the origin, closest is the answer
+		progress := closest.LengthSq() - closest.Dot(w.P)
+		if progress <= GJK_TOLERANCE*math.Max(1, closest.LengthSq()) || simplex.contains(w.P) {
+			break
+		}
+
+		simplex.Points[simplex.Size] = w
+		simplex.Size++
+	}
+
+	result := Result{Distance: closest.Length(), Simplex: simplex, Direction: direction}
+	for i := 0; i < simplex.Size; i++ {
+		result.PointA = *result.PointA.Add(*simplex.Points[i].A.Mul(weights[i]))
+		result.PointB = *result.PointB.Add(*simplex.Points[i].B.Mul(weights[i]))
+	}
+	return result
+}
+
+func (s *Simplex) contains(p vector.Vector3D) bool {
+	for i := 0; i < s.Size; i++ {
+		if s.Points[i].P.Sub(p).LengthSq() < GJK_TOLERANCE {
+			return true
+		}
+	}
+	return false
+}
+
+// reduce finds the point of the simplex closest to the origin, drops the vertices
+// that do not support it and returns the point with its barycentric weights
+func reduce(s *Simplex) (vector.Vector3D, [4]float64) {
+	switch s.Size {
+	case 1:
+		return s.Points[0].P, [4]float64{1}
+	case 2:
+		return reduceSegment(s)
+	case 3:
+		return reduceTriangle(s)
+	default:
+		return reduceTetrahedron(s)
+	}
+}
+
+func reduceSegment(s *Simplex) (vector.Vector3D, [4]float64) {
+	a, b := s.Points[0].P, s.Points[1].P
+	ab := b.Sub(a)
+
+	t := -a.Dot(*ab) / math.Max(ab.LengthSq(), GJK_TOLERANCE)
+	if t <= 0 {
+		s.Size = 1
+		return a, [4]float64{1}
+	}
+	if t >= 1 {
+		s.Points[0] = s.Points[1]
+		s.Size = 1
+		return b, [4]float64{1}
+	}
+	return *a.Add(*ab.Mul(t)), [4]float64{1 - t, t}
+}
+
+// closest point of the triangle abc to the origin (Ericson, Real-Time Collision Detection 5.1.5)
+func reduceTriangle(s *Simplex) (vector.Vector3D, [4]float64) {
+	pa, pb, pc := s.Points[0], s.Points[1], s.Points[2]
+	a, b, c := pa.P, pb.P, pc.P
+
+	ab := *b.Sub(a)
+	ac := *c.Sub(a)
+	ap := *a.Negate()
+
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		s.Size = 1
+		return a, [4]float64{1}
+	}
+
+	bp := *b.Negate()
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		s.Points[0] = pb
+		s.Size = 1
+		return b, [4]float64{1}
+	}
+
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		t := d1 / (d1 - d3)
+		s.Size = 2
+		return *a.Add(*ab.Mul(t)), [4]float64{1 - t, t}
+	}
+
+	cp := *c.Negate()
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		s.Points[0] = pc
+		s.Size = 1
+		return c, [4]float64{1}
+	}
+
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		t := d2 / (d2 - d6)
+		s.Points[1] = pc
+		s.Size = 2
+		return *a.Add(*ac.Mul(t)), [4]float64{1 - t, t}
+	}
+
+	va := d3*d6 - d5*d4
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		t := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		s.Points[0] = pb
+		s.Points[1] = pc
+		s.Size = 2
+		return *b.Add(*c.Sub(b).Mul(t)), [4]float64{1 - t, t}
+	}
+
+	denom := va + vb + vc
+	if math.Abs(denom) < GJK_TOLERANCE {
+		// degenerate triangle, fall back to its closest edge
+		return reduceDegenerateTriangle(s)
+	}
+
+	v := vb / denom
+	w := vc / denom
+	return *a.Add(*ab.Mul(v)).Add(*ac.Mul(w)), [4]float64{1 - v - w, v, w}
+}
+
+func reduceTetrahedron(s *Simplex) (vector.Vector3D, [4]float64) {
+	faces := [4][4]int{
+		{0, 1, 2, 3},
+		{0, 3, 1, 2},
+		{0, 2, 3, 1},
+		{1, 3, 2, 0},
+	}
+
+	inside := true
+	bestDistance := math.Inf(1)
+	var best Simplex
+	var bestPoint vector.Vector3D
+	var bestWeights [4]float64
+
+	for _, f := range faces {
+		if !originOutsideFace(s.Points[f[0]].P, s.Points[f[1]].P, s.Points[f[2]].P, s.Points[f[3]].P) {
+			continue
+		}
+		inside = false
+
+		face := Simplex{Points: [4]SupportPoint{s.Points[f[0]], s.Points[f[1]], s.Points[f[2]]}, Size: 3}
+		point, weights := reduceTriangle(&face)
+		if distance := point.LengthSq(); distance < bestDistance {
+			bestDistance = distance
+			best = face
+			bestPoint = point
+			bestWeights = weights
+		}
+	}
+
+	if inside {
+		return vector.Vector3D{}, [4]float64{}
+	}
+
+	*s = best
+	return bestPoint, bestWeights
+}
+
+// tells if the origin and the point d are on the different sides of the plane abc
+func originOutsideFace(a, b, c, d vector.Vector3D) bool {
+	normal := b.Sub(a).Cross(*c.Sub(a))
+	signOrigin := a.Negate().Dot(*normal)
+	signD := d.Sub(a).Dot(*normal)
+
+	// degenerate tetrahedron, treat every face as a candidate
+	if math.Abs(signD) < GJK_TOLERANCE {
+		return true
+	}
+	return signOrigin*signD < 0
+}
+
+func reduceDegenerateTriangle(s *Simplex) (vector.Vector3D, [4]float64) {
+	edges := [3][2]int{{0, 1}, {1, 2}, {0, 2}}
+
+	bestDistance := math.Inf(1)
+	var best Simplex
+	var bestPoint vector.Vector3D
+	var bestWeights [4]float64
+
+	for _, e := range edges {
+		edge := Simplex{Points: [4]SupportPoint{s.Points[e[0]], s.Points[e[1]]}, Size: 2}
+		point, weights := reduceSegment(&edge)
+		if distance := point.LengthSq(); distance < bestDistance {
+			bestDistance = distance
+			best = edge
+			bestPoint = point
+			bestWeights = weights
+		}
+	}
+
+	*s = best
+	return bestPoint, bestWeights
+}
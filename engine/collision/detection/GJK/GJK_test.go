@@ -0,0 +1,149 @@
+package gjk_test
+
+import (
+	gjk "BachelorThesis/engine/collision/detection/GJK"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+// GJK converges on curved shapes only up to its tolerance
+const tolerance = 1e-4
+
+func TestQuery(t *testing.T) {
+	unit := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	turned := objectstest.Box("turned", vector.Vector3D{}, unit)
+	turned.SetOrientation(*vector.AxisAngleQuaternion(vector.Vector3D{Z: 1}, math.Pi/4))
+
+	cases := []struct {
+		name      string
+		a, b      objects.Object
+		intersect bool
+		distance  float64
+		// nil when the closest points are not unique, only the distance is checked then
+		pointA, pointB *vector.Vector3D
+	}{
+		{
+			name:     "separated spheres",
+			a:        objectstest.Sphere("a", vector.Vector3D{}, 1),
+			b:        objectstest.Sphere("b", vector.Vector3D{X: 5}, 1),
+			distance: 3,
+			pointA:   &vector.Vector3D{X: 1},
+			pointB:   &vector.Vector3D{X: 4},
+		},
+		{
+			name:     "separated spheres diagonally",
+			a:        objectstest.Sphere("a", vector.Vector3D{X: 1, Y: 1, Z: 1}, 0.5),
+			b:        objectstest.Sphere("b", vector.Vector3D{X: 4, Y: 5, Z: 1}, 1.5),
+			distance: 3,
+			pointA:   &vector.Vector3D{X: 1.3, Y: 1.4, Z: 1},
+			pointB:   &vector.Vector3D{X: 3.1, Y: 3.8, Z: 1},
+		},
+		{
+			name:      "touching spheres",
+			a:         objectstest.Sphere("a", vector.Vector3D{}, 1),
+			b:         objectstest.Sphere("b", vector.Vector3D{Y: 2}, 1),
+			intersect: true,
+		},
+		{
+			name:      "overlapping spheres",
+			a:         objectstest.Sphere("a", vector.Vector3D{}, 1),
+			b:         objectstest.Sphere("b", vector.Vector3D{Z: -1.2}, 1),
+			intersect: true,
+		},
+		{
+			name:     "separated box and sphere",
+			a:        objectstest.Box("a", vector.Vector3D{}, unit),
+			b:        objectstest.Sphere("b", vector.Vector3D{X: 3, Y: 0.5}, 1),
+			distance: 1,
+			pointA:   &vector.Vector3D{X: 1, Y: 0.5},
+			pointB:   &vector.Vector3D{X: 2, Y: 0.5},
+		},
+		{
+			name:     "sphere off the corner of a box",
+			a:        objectstest.Sphere("a", vector.Vector3D{X: 3, Y: 3, Z: 3}, 1),
+			b:        objectstest.Box("b", vector.Vector3D{}, unit),
+			distance: 2*math.Sqrt(3) - 1,
+			pointA:   vector.Vector3D{X: 3, Y: 3, Z: 3}.AddFloat(-1 / math.Sqrt(3)),
+			pointB:   &vector.Vector3D{X: 1, Y: 1, Z: 1},
+		},
+		{
+			name:      "overlapping box and sphere",
+			a:         objectstest.Box("a", vector.Vector3D{}, unit),
+			b:         objectstest.Sphere("b", vector.Vector3D{X: 1.5}, 1),
+			intersect: true,
+		},
+		{
+			name:     "sphere off a turned box",
+			a:        turned,
+			b:        objectstest.Sphere("b", vector.Vector3D{X: 3}, 1),
+			distance: 2 - math.Sqrt2,
+			pointA:   &vector.Vector3D{X: math.Sqrt2},
+			pointB:   &vector.Vector3D{X: 2},
+		},
+		{
+			name: "separated capsule and box",
+			a:    objectstest.Capsule("a", vector.Vector3D{X: 3}, 0.5, 1),
+			b:    objectstest.Box("b", vector.Vector3D{}, unit),
+			// the segment is parallel to a face, the closest points are not unique
+			distance: 1.5,
+		},
+		{
+			name:     "capsule end over a box",
+			a:        objectstest.Capsule("a", vector.Vector3D{Y: 4}, 0.5, 1),
+			b:        objectstest.Box("b", vector.Vector3D{}, unit),
+			distance: 1.5,
+			pointA:   &vector.Vector3D{Y: 2.5},
+			pointB:   &vector.Vector3D{Y: 1},
+		},
+		{
+			name:      "overlapping capsule and box",
+			a:         objectstest.Capsule("a", vector.Vector3D{X: 1.2, Y: 0.3}, 0.5, 1),
+			b:         objectstest.Box("b", vector.Vector3D{}, unit),
+			intersect: true,
+		},
+		{
+			name:      "capsule through a box",
+			a:         objectstest.Capsule("a", vector.Vector3D{}, 0.2, 3),
+			b:         objectstest.Box("b", vector.Vector3D{}, unit),
+			intersect: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := gjk.Query(tc.a, tc.b)
+			if result.Intersect != tc.intersect {
+				t.Fatalf("Intersect = %v, want %v (distance %v)", result.Intersect, tc.intersect, result.Distance)
+			}
+			if tc.intersect {
+				if result.Simplex.Size == 0 {
+					t.Error("intersecting query without a simplex")
+				}
+				return
+			}
+
+			if math.Abs(result.Distance-tc.distance) > tolerance {
+				t.Errorf("distance = %v, want %v", result.Distance, tc.distance)
+			}
+			// the closest points are always as far apart as the distance
+			if gap := result.PointB.Sub(result.PointA).Length(); math.Abs(gap-result.Distance) > tolerance {
+				t.Errorf("closest points %v and %v are %v apart, distance is %v", result.PointA, result.PointB, gap, result.Distance)
+			}
+			if tc.pointA != nil && result.PointA.Sub(*tc.pointA).Length() > tolerance {
+				t.Errorf("point A = %v, want %v", result.PointA, *tc.pointA)
+			}
+			if tc.pointB != nil && result.PointB.Sub(*tc.pointB).Length() > tolerance {
+				t.Errorf("point B = %v, want %v", result.PointB, *tc.pointB)
+			}
+
+			// swapping the shapes swaps the points
+			swapped := gjk.Query(tc.b, tc.a)
+			if swapped.Intersect || math.Abs(swapped.Distance-result.Distance) > tolerance {
+				t.Errorf("swapped query: intersect %v, distance %v", swapped.Intersect, swapped.Distance)
+			}
+		})
+	}
+}
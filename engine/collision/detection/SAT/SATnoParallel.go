@@ -1,23 +1,66 @@
 package sat
 
 import (
-	tgs "BachelorThesis/engine/collision/resolving/TGS"
-	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/collision/contact"
+	epa "BachelorThesis/engine/collision/detection/EPA"
+	"BachelorThesis/engine/collision/resolving"
 	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
 	"log"
 	"math"
 )
 
 func SATNoParallel(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	satPair(aID, bID, objectPool, resolveAlgorithm)
+}
+
+// satPair picks the test for the pair of shapes. Pairs without a dedicated
+// test (cylinders, capsule-box) go through GJK + EPA, which only needs support functions
+func satPair(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	if _, ok := (*objectPool)[bID].(*objects.Plane); ok {
+		SATPlane(aID, bID, objectPool, resolveAlgorithm)
+		return
+	}
+	if _, ok := (*objectPool)[aID].(*objects.Plane); ok {
+		SATPlane(bID, aID, objectPool, resolveAlgorithm)
+		return
+	}
+
 	switch (*objectPool)[aID].(type) {
 	case *objects.Sphere:
 		switch (*objectPool)[bID].(type) {
 		case *objects.Sphere:
 			satSphereSphere(aID, bID, objectPool, resolveAlgorithm)
+		case *objects.Box:
+			satBoxSphere(bID, aID, objectPool, resolveAlgorithm)
+		case *objects.Capsule:
+			satCapsuleSphere(bID, aID, objectPool, resolveAlgorithm)
+		default:
+			epa.EPANoParallel(aID, bID, objectPool, resolveAlgorithm)
+		}
+
+	case *objects.Box:
+		switch (*objectPool)[bID].(type) {
+		case *objects.Sphere:
+			satBoxSphere(aID, bID, objectPool, resolveAlgorithm)
+		case *objects.Box:
+			satBoxBox(aID, bID, objectPool, resolveAlgorithm)
+		default:
+			epa.EPANoParallel(aID, bID, objectPool, resolveAlgorithm)
+		}
+
+	case *objects.Capsule:
+		switch (*objectPool)[bID].(type) {
+		case *objects.Sphere:
+			satCapsuleSphere(aID, bID, objectPool, resolveAlgorithm)
+		case *objects.Capsule:
+			satCapsuleCapsule(aID, bID, objectPool, resolveAlgorithm)
+		default:
+			epa.EPANoParallel(aID, bID, objectPool, resolveAlgorithm)
 		}
 
 	default:
-		log.Panicf("Unknown object type: %T", (*objectPool)[aID])
+		epa.EPANoParallel(aID, bID, objectPool, resolveAlgorithm)
 	}
 }
 
@@ -72,6 +115,7 @@ func satSphereSphere(aID, bID int, objectPool *[]objects.Object, resolveAlgorith
 		distance := math.Sqrt(distanceSq)
 
 		var penetrationDepth float64
+		normal := vector.Vector3D{X: 1, Y: 0, Z: 0}
 
 		if distance == 0 {
 			// Центры сфер совпадают. Это особый случай.
@@ -83,6 +127,7 @@ func satSphereSphere(aID, bID int, objectPool *[]objects.Object, resolveAlgorith
 			// Нормаль столкновения - это нормализованный вектор от A к B (или от B к A)
 			// Здесь нормаль будет указывать от A на B
 			penetrationDepth = sumRadii - distance
+			normal = vector.Vector3D{X: axisX / distance, Y: axisY / distance, Z: axisZ / distance}
 		}
 
 		// Убедимся, что глубина проникновения не отрицательная (из-за ошибок float)
@@ -101,22 +146,15 @@ func satSphereSphere(aID, bID int, objectPool *[]objects.Object, resolveAlgorith
 		// Они пересекаются, если radiusA >= distance - radiusB, что эквивалентно radiusA + radiusB >= distance.
 		// Это условие мы уже проверили.
 
-		// Вызываем резолвер
-		switch resolveAlgorithm {
-		case constants.PGS:
-			switch constants.ResolveAlgoType {
-			case constants.N:
-				tgs.TGSNoParallel(aID, bID, objectPool)
-			case constants.PNT:
-				// TODO: pgs.PGSParallelWithNormalAndTangent(aID, bID, objectPool, collisionNormal, penetrationDepth, ...)
-			default:
-				log.Panicf("Unknown ResolveAlgoType for PGS: %s", constants.ResolveAlgoType)
-			}
-
-		case constants.NoAlgo:
-			return // Просто выходим, если алгоритм не задан
-		default:
-			log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
+		// Вызываем резолвер, передавая ему найденный контакт
+		c := &contact.Contact{
+			AID:    aID,
+			BID:    bID,
+			Normal: normal,
+			Depth:  penetrationDepth,
+			PointA: *posA.Add(*normal.Mul(radiusA)),
+			PointB: *posB.Sub(*normal.Mul(radiusB)),
 		}
+		resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
 	}
 }
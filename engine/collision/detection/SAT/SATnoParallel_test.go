@@ -0,0 +1,131 @@
+package sat
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-6
+
+// the tests check the normal response, friction and bounces of the default material would hide it
+var inelastic = objects.Material{Name: "Inelastic", Density: 1}
+
+func TestSatSphereSphere(t *testing.T) {
+	cases := []struct {
+		name       string
+		posB       vector.Vector3D
+		velA, velB vector.Vector3D
+		// touching pairs are resolved along the normal, A to B
+		touching bool
+		normal   vector.Vector3D
+		// the relative normal velocity after the resolver
+		separating float64
+	}{
+		{
+			name: "apart",
+			posB: vector.Vector3D{X: 2.5},
+			velA: vector.Vector3D{X: 1},
+			velB: vector.Vector3D{X: -1},
+		},
+		{
+			name: "apart diagonally",
+			posB: vector.Vector3D{X: 1.5, Y: 1.5, Z: 1.5},
+			velA: vector.Vector3D{Y: 1},
+		},
+		{
+			name:     "touching head-on",
+			posB:     vector.Vector3D{X: 2},
+			velA:     vector.Vector3D{X: 1},
+			velB:     vector.Vector3D{X: -1},
+			touching: true,
+			normal:   vector.Vector3D{X: 1},
+		},
+		{
+			name: "overlapping at rest",
+			posB: vector.Vector3D{Y: -1.5},
+			// the overlap is pushed out at the Baumgarte velocity
+			touching:   true,
+			normal:     vector.Vector3D{Y: -1},
+			separating: constraint.BAUMGARTE_BIAS * (0.5 - constraint.SLOP) / constants.TimeStep,
+		},
+		{
+			name:       "overlapping diagonally",
+			posB:       vector.Vector3D{X: 1, Z: 1},
+			touching:   true,
+			normal:     vector.Vector3D{X: math.Sqrt2 / 2, Z: math.Sqrt2 / 2},
+			separating: constraint.BAUMGARTE_BIAS * (2 - math.Sqrt2 - constraint.SLOP) / constants.TimeStep,
+		},
+		{
+			name: "concentric",
+			posB: vector.Vector3D{},
+			// any normal works, the X axis is picked
+			touching:   true,
+			normal:     vector.Vector3D{X: 1},
+			separating: constraint.BAUMGARTE_BIAS * (2 - constraint.SLOP) / constants.TimeStep,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// new ids in every case, the contact of the previous one is not warm started
+			a := objectstest.Sphere(fmt.Sprintf("a_%d", i), vector.Vector3D{}, 1)
+			b := objectstest.Sphere(fmt.Sprintf("b_%d", i), tc.posB, 1)
+			a.SetMaterial(inelastic)
+			b.SetMaterial(inelastic)
+			a.ApplyVelocity(tc.velA)
+			b.ApplyVelocity(tc.velB)
+			pool := []objects.Object{a, b}
+
+			contact.NextFrame()
+			satSphereSphere(0, 1, &pool, constants.PGS)
+			resolving.Solve(&pool, constants.PGS)
+
+			velA, _ := a.GetVelocity()
+			velB, _ := b.GetVelocity()
+
+			if !tc.touching {
+				if *velA != tc.velA || *velB != tc.velB {
+					t.Errorf("separate spheres were resolved: %v, %v", *velA, *velB)
+				}
+				return
+			}
+
+			relative := velB.Sub(*velA)
+			if got := relative.Dot(tc.normal); math.Abs(got-tc.separating) > tolerance {
+				t.Errorf("relative normal velocity = %v, want %v", got, tc.separating)
+			}
+			if tangent := relative.Sub(*tc.normal.Mul(relative.Dot(tc.normal))); tangent.Length() > tolerance {
+				t.Errorf("the spheres got a tangential velocity %v", *tangent)
+			}
+			// equal masses, the impulses cancel out
+			if momentum := velA.Add(*velB).Sub(*tc.velA.Add(tc.velB)); momentum.Length() > tolerance {
+				t.Errorf("momentum changed by %v", *momentum)
+			}
+		})
+	}
+}
+
+// BenchmarkNarrow runs the narrow phase on the pairs that really touch, without the resolver
+func BenchmarkNarrow(b *testing.B) {
+	for _, n := range objectstest.Sizes {
+		b.Run(objectstest.SizeName(n), func(b *testing.B) {
+			pool := objectstest.RandomSpheres(n, 1)
+			pairs := objectstest.TouchingPairs(pool)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, pair := range pairs {
+					satPair(pair[0], pair[1], &pool, constants.NoAlgo)
+				}
+			}
+		})
+	}
+}
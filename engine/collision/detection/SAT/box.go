@@ -0,0 +1,403 @@
+package sat
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"math"
+)
+
+const (
+	// оси из векторных произведений почти параллельных рёбер не проверяются
+	EDGE_AXIS_EPSILON = 1e-6
+	// при равных проникновениях предпочитаем оси граней осям рёбер
+	EDGE_AXIS_BIAS = 1.05
+)
+
+func satBoxBox(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	objA := (*objectPool)[aID]
+	boxA, okA := objA.(*objects.Box)
+	if !okA {
+		log.Panicf("Object %d (ID: %s) is not a Box", aID, objA.GetId())
+		return
+	}
+
+	objB := (*objectPool)[bID]
+	boxB, okB := objB.(*objects.Box)
+	if !okB {
+		log.Panicf("Object %d (ID: %s) is not a Box", bID, objB.GetId())
+		return
+	}
+
+	m, ok := boxBoxManifold(boxA, boxB)
+	if !ok {
+		return
+	}
+
+	m.AID = aID
+	m.BID = bID
+	resolving.ResolveManifold(m, objectPool, resolveAlgorithm)
+}
+
+func satBoxSphere(boxID, sphereID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	objA := (*objectPool)[boxID]
+	box, okA := objA.(*objects.Box)
+	if !okA {
+		log.Panicf("Object %d (ID: %s) is not a Box", boxID, objA.GetId())
+		return
+	}
+
+	objB := (*objectPool)[sphereID]
+	sphere, okB := objB.(*objects.Sphere)
+	if !okB {
+		log.Panicf("Object %d (ID: %s) is not a Sphere", sphereID, objB.GetId())
+		return
+	}
+
+	c, ok := boxSphereContact(box, sphere)
+	if !ok {
+		return
+	}
+
+	c.AID = boxID
+	c.BID = sphereID
+	resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
+}
+
+// boxBoxManifold проверяет 15 осей: 3 грани A, 3 грани B и 9 произведений рёбер.
+// Нормаль контакта - ось с наименьшим перекрытием, направленная от A к B.
+// Для оси грани точки контакта - грань другой коробки, обрезанная по опорной грани (до 4 точек),
+// для оси рёбер - одна точка между рёбрами
+func boxBoxManifold(boxA, boxB *objects.Box) (*contact.Manifold, bool) {
+	posA, errA := boxA.GetPosition()
+	posB, errB := boxB.GetPosition()
+	if errA != nil || errB != nil {
+		log.Printf("SAT: failed to get positions of %s and %s: %v, %v", boxA.GetId(), boxB.GetId(), errA, errB)
+		return nil, false
+	}
+
+	rotA := boxA.GetOrientation()
+	rotB := boxB.GetOrientation()
+	halfA := boxA.GetHalfExtents()
+	halfB := boxB.GetHalfExtents()
+
+	axesA := [3]vector.Vector3D{*rotA.Column(0), *rotA.Column(1), *rotA.Column(2)}
+	axesB := [3]vector.Vector3D{*rotB.Column(0), *rotB.Column(1), *rotB.Column(2)}
+
+	toB := posB.Sub(*posA)
+
+	bestDepth := math.Inf(1)
+	bestAxis := vector.Vector3D{}
+	// 0..2 - грани A, 3..5 - грани B, 6..14 - рёбра (3*i + j)
+	bestIndex := -1
+
+	testAxis := func(axis vector.Vector3D, index int) bool {
+		radiusA := projectBox(halfA, axesA, axis)
+		radiusB := projectBox(halfB, axesB, axis)
+		distance := toB.Dot(axis)
+
+		depth := radiusA + radiusB - math.Abs(distance)
+		if depth < 0 {
+			return false
+		}
+
+		biasedDepth := depth
+		if index >= 6 {
+			biasedDepth *= EDGE_AXIS_BIAS
+		}
+
+		if biasedDepth < bestDepth {
+			bestDepth = biasedDepth
+			if distance < 0 {
+				axis = *axis.Negate()
+			}
+			bestAxis = axis
+			bestIndex = index
+		}
+		return true
+	}
+
+	for i := 0; i < 3; i++ {
+		if !testAxis(axesA[i], i) {
+			return nil, false
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if !testAxis(axesB[i], 3+i) {
+			return nil, false
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			axis := axesA[i].Cross(axesB[j])
+			if axis.Length() < EDGE_AXIS_EPSILON {
+				continue
+			}
+			if !testAxis(*axis.Normalize(), 6+3*i+j) {
+				return nil, false
+			}
+		}
+	}
+
+	depth := bestDepth
+	if bestIndex >= 6 {
+		depth /= EDGE_AXIS_BIAS
+	}
+	normal := bestAxis
+
+	m := &contact.Manifold{Normal: normal}
+
+	switch {
+	case bestIndex < 3:
+		// грань A опорная, B касается её своей гранью
+		m.Points = clipFaces(*posA, halfA, axesA, bestIndex, normal, *posB, halfB, axesB, false)
+	case bestIndex < 6:
+		// грань B опорная, её нормаль смотрит на A
+		m.Points = clipFaces(*posB, halfB, axesB, bestIndex-3, *normal.Negate(), *posA, halfA, axesA, true)
+	}
+
+	if len(m.Points) == 0 {
+		// ребро-ребро или обрезка ничего не оставила: одна точка
+		p := contact.Point{Depth: depth, Feature: boxFeature(bestIndex, BOX_EDGE_TAG)}
+
+		switch {
+		case bestIndex < 3:
+			// грань A: самая глубокая вершина B
+			p.PointB = *boxB.Support(*normal.Negate())
+			p.PointA = *p.PointB.Add(*normal.Mul(depth))
+		case bestIndex < 6:
+			// грань B: самая глубокая вершина A
+			p.PointA = *boxA.Support(normal)
+			p.PointB = *p.PointA.Sub(*normal.Mul(depth))
+		default:
+			// ближайшие точки двух опорных рёбер
+			i := (bestIndex - 6) / 3
+			j := (bestIndex - 6) % 3
+			edgeA := supportEdgeCenter(*posA, halfA, axesA, i, normal)
+			edgeB := supportEdgeCenter(*posB, halfB, axesB, j, *normal.Negate())
+			p.PointA, p.PointB = closestPointsOnLines(edgeA, axesA[i], edgeB, axesB[j])
+		}
+
+		m.Points = []contact.Point{p}
+	}
+
+	m.Reduce()
+
+	return m, true
+}
+
+// clipFaces обрезает грань коробки incident, наиболее обращённую к опорной грани, по боковым
+// граням опорной коробки (Сазерленд-Ходжман) и оставляет вершины, ушедшие за опорную грань.
+// referenceNormal направлена от опорной коробки к другой. Если incidentIsA, то точки
+// другой коробки - это точки A, иначе B
+func clipFaces(refPos, refHalf vector.Vector3D, refAxes [3]vector.Vector3D, refAxis int, referenceNormal vector.Vector3D,
+	incPos, incHalf vector.Vector3D, incAxes [3]vector.Vector3D, incidentIsA bool) []contact.Point {
+	refHalves := [3]float64{refHalf.X, refHalf.Y, refHalf.Z}
+	incHalves := [3]float64{incHalf.X, incHalf.Y, incHalf.Z}
+
+	// грань другой коробки, нормаль которой ближе всего к -referenceNormal
+	incAxis := 0
+	for k := 1; k < 3; k++ {
+		if math.Abs(incAxes[k].Dot(referenceNormal)) > math.Abs(incAxes[incAxis].Dot(referenceNormal)) {
+			incAxis = k
+		}
+	}
+	incNormal := incAxes[incAxis]
+	if incNormal.Dot(referenceNormal) > 0 {
+		incNormal = *incNormal.Negate()
+	}
+
+	u, v := (incAxis+1)%3, (incAxis+2)%3
+	incCenter := incPos.Add(*incNormal.Mul(incHalves[incAxis]))
+	polygon := make([]clipVertex, 0, 8)
+	for k, signs := range [4][2]float64{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}} {
+		point := incCenter.Add(*incAxes[u].Mul(signs[0] * incHalves[u]))
+		point = point.Add(*incAxes[v].Mul(signs[1] * incHalves[v]))
+		polygon = append(polygon, clipVertex{point: *point, tag: k})
+	}
+
+	// четыре боковые грани опорной коробки
+	side := 0
+	for _, k := range [2]int{(refAxis + 1) % 3, (refAxis + 2) % 3} {
+		for _, sign := range [2]float64{1, -1} {
+			normal := refAxes[k].Mul(sign)
+			offset := normal.Dot(refPos) + refHalves[k]
+			polygon = clipPolygon(polygon, *normal, offset, side)
+			side++
+		}
+	}
+
+	axisIndex := refAxis
+	if incidentIsA {
+		axisIndex += 3
+	}
+
+	refFace := referenceNormal.Dot(refPos) + refHalves[refAxis]
+	points := make([]contact.Point, 0, len(polygon))
+	for _, vertex := range polygon {
+		separation := referenceNormal.Dot(vertex.point) - refFace
+		if separation > 0 {
+			continue
+		}
+
+		// проекция на опорную грань
+		onReference := *vertex.point.Sub(*referenceNormal.Mul(separation))
+		p := contact.Point{Depth: -separation, Feature: boxFeature(axisIndex, vertex.tag)}
+		if incidentIsA {
+			p.PointA, p.PointB = vertex.point, onReference
+		} else {
+			p.PointA, p.PointB = onReference, vertex.point
+		}
+		points = append(points, p)
+	}
+
+	return points
+}
+
+// вершина обрезаемой грани: исходные вершины помечены 0..3, точки на рёбрах -
+// 4 + 4*(боковая грань) + (метка начала ребра)
+type clipVertex struct {
+	point vector.Vector3D
+	tag   int
+}
+
+// метка точки между рёбрами, не совпадает с метками clipVertex
+const BOX_EDGE_TAG = 31
+
+// feature точки контакта двух коробок: ось контакта и метка точки
+func boxFeature(axisIndex, tag int) int {
+	return 1 + axisIndex*32 + tag
+}
+
+// clipPolygon оставляет часть многоугольника, где normal·p <= offset
+func clipPolygon(polygon []clipVertex, normal vector.Vector3D, offset float64, side int) []clipVertex {
+	clipped := make([]clipVertex, 0, len(polygon)+1)
+
+	for i, current := range polygon {
+		next := polygon[(i+1)%len(polygon)]
+		distCurrent := normal.Dot(current.point) - offset
+		distNext := normal.Dot(next.point) - offset
+
+		if distCurrent <= 0 {
+			clipped = append(clipped, current)
+		}
+		if distCurrent*distNext < 0 {
+			t := distCurrent / (distCurrent - distNext)
+			point := current.point.Add(*next.point.Sub(current.point).Mul(t))
+			clipped = append(clipped, clipVertex{point: *point, tag: 4 + 4*side + current.tag%4})
+		}
+	}
+
+	return clipped
+}
+
+// boxSphereContact ищет ближайшую к центру сферы точку коробки в локальных координатах коробки.
+// Нормаль направлена от коробки к сфере
+func boxSphereContact(box *objects.Box, sphere *objects.Sphere) (*contact.Contact, bool) {
+	boxPos, errA := box.GetPosition()
+	spherePos, errB := sphere.GetPosition()
+	if errA != nil || errB != nil {
+		log.Printf("SAT: failed to get positions of %s and %s: %v, %v", box.GetId(), sphere.GetId(), errA, errB)
+		return nil, false
+	}
+
+	rotation := box.GetOrientation()
+	half := box.GetHalfExtents()
+	radius := sphere.GetRadius()
+
+	local := rotation.Transpose().MulVector(*spherePos.Sub(*boxPos))
+	closest := vector.Vector3D{
+		X: math.Max(-half.X, math.Min(half.X, local.X)),
+		Y: math.Max(-half.Y, math.Min(half.Y, local.Y)),
+		Z: math.Max(-half.Z, math.Min(half.Z, local.Z)),
+	}
+
+	var localNormal vector.Vector3D
+	var depth float64
+
+	outside := closest.Sub(*local)
+	if outside.LengthSq() > 0 {
+		// центр снаружи коробки
+		distance := outside.Length()
+		if distance > radius {
+			return nil, false
+		}
+
+		localNormal = *local.Sub(closest).Normalize()
+		depth = radius - distance
+	} else {
+		// центр внутри: выталкиваем через ближайшую грань
+		distances := [3]float64{half.X - math.Abs(local.X), half.Y - math.Abs(local.Y), half.Z - math.Abs(local.Z)}
+		signs := [3]float64{math.Copysign(1, local.X), math.Copysign(1, local.Y), math.Copysign(1, local.Z)}
+
+		axis := 0
+		for k := 1; k < 3; k++ {
+			if distances[k] < distances[axis] {
+				axis = k
+			}
+		}
+
+		switch axis {
+		case 0:
+			localNormal = vector.Vector3D{X: signs[0]}
+			closest.X = signs[0] * half.X
+		case 1:
+			localNormal = vector.Vector3D{Y: signs[1]}
+			closest.Y = signs[1] * half.Y
+		case 2:
+			localNormal = vector.Vector3D{Z: signs[2]}
+			closest.Z = signs[2] * half.Z
+		}
+		depth = radius + distances[axis]
+	}
+
+	normal := rotation.MulVector(localNormal)
+
+	return &contact.Contact{
+		Normal: *normal,
+		Depth:  depth,
+		PointA: *boxPos.Add(*rotation.MulVector(closest)),
+		PointB: *spherePos.Sub(*normal.Mul(radius)),
+	}, true
+}
+
+// проекция коробки на ось (половина длины отрезка)
+func projectBox(half vector.Vector3D, axes [3]vector.Vector3D, axis vector.Vector3D) float64 {
+	return half.X*math.Abs(axes[0].Dot(axis)) +
+		half.Y*math.Abs(axes[1].Dot(axis)) +
+		half.Z*math.Abs(axes[2].Dot(axis))
+}
+
+// середина ребра коробки, параллельного оси edgeAxis и самого дальнего в направлении direction
+func supportEdgeCenter(position, half vector.Vector3D, axes [3]vector.Vector3D, edgeAxis int, direction vector.Vector3D) vector.Vector3D {
+	halves := [3]float64{half.X, half.Y, half.Z}
+
+	point := position
+	for k := 0; k < 3; k++ {
+		if k == edgeAxis {
+			continue
+		}
+		point = *point.Add(*axes[k].Mul(math.Copysign(halves[k], axes[k].Dot(direction))))
+	}
+	return point
+}
+
+// ближайшие точки двух прямых p1 + s*d1 и p2 + t*d2 (d1, d2 единичные и не параллельные)
+func closestPointsOnLines(p1, d1, p2, d2 vector.Vector3D) (vector.Vector3D, vector.Vector3D) {
+	r := p1.Sub(p2)
+	b := d1.Dot(d2)
+	c := d1.Dot(*r)
+	f := d2.Dot(*r)
+
+	denom := 1 - b*b
+	if denom < EDGE_AXIS_EPSILON {
+		return p1, p2
+	}
+
+	s := (b*f - c) / denom
+	t := (f - b*c) / denom
+
+	return *p1.Add(*d1.Mul(s)), *p2.Add(*d2.Mul(t))
+}
@@ -0,0 +1,157 @@
+package sat
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+// turned returns the box turned by angle (radians) around the axis
+func turned(box *objects.Box, axis vector.Vector3D, angle float64) *objects.Box {
+	box.SetOrientation(*vector.AxisAngleQuaternion(axis, angle))
+	box.Update(0)
+	return box
+}
+
+func TestBoxBoxManifold(t *testing.T) {
+	unit := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	// the top edge of a box turned around Z and the bottom edge of one turned around X cross
+	// at the height of the diagonal
+	diagonal := math.Sqrt2
+
+	cases := []struct {
+		name   string
+		a, b   *objects.Box
+		hit    bool
+		normal vector.Vector3D
+		depth  float64
+		points int
+	}{
+		{
+			name: "apart",
+			a:    objectstest.Box("a", vector.Vector3D{}, unit),
+			b:    objectstest.Box("b", vector.Vector3D{X: 0.3, Y: 2.1}, unit),
+		},
+		{
+			// the face of B lies on the face of A, the overlap is a square with 4 corners
+			name:   "face on face",
+			a:      objectstest.Box("a", vector.Vector3D{}, unit),
+			b:      objectstest.Box("b", vector.Vector3D{X: 0.3, Y: 1.95, Z: -0.2}, unit),
+			hit:    true,
+			normal: vector.Vector3D{Y: 1},
+			depth:  0.05,
+			points: 4,
+		},
+		{
+			name:   "face of B",
+			a:      objectstest.Box("a", vector.Vector3D{}, vector.Vector3D{X: 0.5, Y: 0.5, Z: 0.5}),
+			b:      objectstest.Box("b", vector.Vector3D{Y: -1.4}, vector.Vector3D{X: 3, Y: 1, Z: 3}),
+			hit:    true,
+			normal: vector.Vector3D{Y: -1},
+			depth:  0.1,
+			points: 4,
+		},
+		{
+			name:   "edge on edge",
+			a:      turned(objectstest.Box("a", vector.Vector3D{}, unit), vector.Vector3D{Z: 1}, math.Pi/4),
+			b:      turned(objectstest.Box("b", vector.Vector3D{Y: 2*diagonal - 0.1}, unit), vector.Vector3D{X: 1}, math.Pi/4),
+			hit:    true,
+			normal: vector.Vector3D{Y: 1},
+			depth:  0.1,
+			points: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, hit := boxBoxManifold(tc.a, tc.b)
+			if hit != tc.hit {
+				t.Fatalf("hit = %v, want %v", hit, tc.hit)
+			}
+			if !hit {
+				return
+			}
+
+			if m.Normal.Sub(tc.normal).Length() > tolerance {
+				t.Errorf("normal = %v, want %v", m.Normal, tc.normal)
+			}
+			if len(m.Points) != tc.points {
+				t.Fatalf("%d points, want %d", len(m.Points), tc.points)
+			}
+			for _, p := range m.Points {
+				if math.Abs(p.Depth-tc.depth) > tolerance {
+					t.Errorf("depth = %v, want %v", p.Depth, tc.depth)
+				}
+				// the points of A and B are as deep in each other as the depth
+				if gap := p.PointA.Sub(p.PointB).Dot(m.Normal); math.Abs(gap-p.Depth) > tolerance {
+					t.Errorf("points %v and %v are %v deep along the normal, depth is %v", p.PointA, p.PointB, gap, p.Depth)
+				}
+			}
+		})
+	}
+}
+
+func TestBoxSphereContact(t *testing.T) {
+	cases := []struct {
+		name   string
+		center vector.Vector3D
+		hit    bool
+		normal vector.Vector3D
+		depth  float64
+	}{
+		{
+			name:   "apart",
+			center: vector.Vector3D{X: 1.6},
+		},
+		{
+			name:   "outside a face",
+			center: vector.Vector3D{X: 1.3, Y: 0.2},
+			hit:    true,
+			normal: vector.Vector3D{X: 1},
+			depth:  0.2,
+		},
+		{
+			name:   "outside a corner",
+			center: vector.Vector3D{X: 1.2, Y: 1.2},
+			hit:    true,
+			normal: vector.Vector3D{X: math.Sqrt2 / 2, Y: math.Sqrt2 / 2},
+			depth:  0.5 - 0.2*math.Sqrt2,
+		},
+		{
+			// the center is pushed out through the closest face
+			name:   "inside",
+			center: vector.Vector3D{Y: -0.7, Z: 0.3},
+			hit:    true,
+			normal: vector.Vector3D{Y: -1},
+			depth:  0.8,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			box := objectstest.Box("box", vector.Vector3D{}, vector.Vector3D{X: 1, Y: 1, Z: 1})
+			sphere := objectstest.Sphere("sphere", tc.center, 0.5)
+
+			c, hit := boxSphereContact(box, sphere)
+			if hit != tc.hit {
+				t.Fatalf("hit = %v, want %v", hit, tc.hit)
+			}
+			if !hit {
+				return
+			}
+
+			if c.Normal.Sub(tc.normal).Length() > tolerance {
+				t.Errorf("normal = %v, want %v", c.Normal, tc.normal)
+			}
+			if math.Abs(c.Depth-tc.depth) > tolerance {
+				t.Errorf("depth = %v, want %v", c.Depth, tc.depth)
+			}
+			// the point of B is on the sphere
+			if distance := c.PointB.Sub(tc.center).Length(); math.Abs(distance-0.5) > tolerance {
+				t.Errorf("point %v is %v from the center of the sphere", c.PointB, distance)
+			}
+		})
+	}
+}
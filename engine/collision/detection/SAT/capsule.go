@@ -0,0 +1,227 @@
+package sat
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"math"
+)
+
+const (
+	// точность для вырожденных отрезков и совпадающих центров
+	SEGMENT_EPSILON = 1e-9
+	// синус угла, при котором отрезки капсул считаются параллельными
+	PARALLEL_EPSILON = 1e-3
+)
+
+func satCapsuleCapsule(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	objA := (*objectPool)[aID]
+	capsuleA, okA := objA.(*objects.Capsule)
+	if !okA {
+		log.Panicf("Object %d (ID: %s) is not a Capsule", aID, objA.GetId())
+		return
+	}
+
+	objB := (*objectPool)[bID]
+	capsuleB, okB := objB.(*objects.Capsule)
+	if !okB {
+		log.Panicf("Object %d (ID: %s) is not a Capsule", bID, objB.GetId())
+		return
+	}
+
+	m, ok := capsuleCapsuleManifold(capsuleA, capsuleB)
+	if !ok {
+		return
+	}
+
+	m.AID = aID
+	m.BID = bID
+	resolving.ResolveManifold(m, objectPool, resolveAlgorithm)
+}
+
+func satCapsuleSphere(capsuleID, sphereID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	objA := (*objectPool)[capsuleID]
+	capsule, okA := objA.(*objects.Capsule)
+	if !okA {
+		log.Panicf("Object %d (ID: %s) is not a Capsule", capsuleID, objA.GetId())
+		return
+	}
+
+	objB := (*objectPool)[sphereID]
+	sphere, okB := objB.(*objects.Sphere)
+	if !okB {
+		log.Panicf("Object %d (ID: %s) is not a Sphere", sphereID, objB.GetId())
+		return
+	}
+
+	c, ok := capsuleSphereContact(capsule, sphere)
+	if !ok {
+		return
+	}
+
+	c.AID = capsuleID
+	c.BID = sphereID
+	resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
+}
+
+// капсулы сталкиваются, если расстояние между их отрезками меньше суммы радиусов
+func capsuleCapsuleContact(capsuleA, capsuleB *objects.Capsule) (*contact.Contact, bool) {
+	topA, bottomA := capsuleA.GetSegment()
+	topB, bottomB := capsuleB.GetSegment()
+
+	pointA, pointB := closestPointsOnSegments(bottomA, topA, bottomB, topB)
+
+	return roundedContact(pointA, capsuleA.GetRadius(), pointB, capsuleB.GetRadius())
+}
+
+// параллельные капсулы лежат друг на друге отрезком, тогда точек контакта две - концы общей части
+func capsuleCapsuleManifold(capsuleA, capsuleB *objects.Capsule) (*contact.Manifold, bool) {
+	c, ok := capsuleCapsuleContact(capsuleA, capsuleB)
+	if !ok {
+		return nil, false
+	}
+	m := c.Manifold()
+
+	topA, bottomA := capsuleA.GetSegment()
+	topB, bottomB := capsuleB.GetSegment()
+	dA := topA.Sub(bottomA)
+	dB := topB.Sub(bottomB)
+
+	lengthSq := dA.LengthSq()
+	if lengthSq < SEGMENT_EPSILON || dB.LengthSq() < SEGMENT_EPSILON {
+		return m, true
+	}
+	if dA.Normalize().Cross(*dB.Normalize()).Length() > PARALLEL_EPSILON {
+		return m, true
+	}
+
+	// общая часть - проекция отрезка B на отрезок A
+	t1 := bottomB.Sub(bottomA).Dot(*dA) / lengthSq
+	t2 := topB.Sub(bottomA).Dot(*dA) / lengthSq
+	low := math.Max(0, math.Min(t1, t2))
+	high := math.Min(1, math.Max(t1, t2))
+	if high-low < SEGMENT_EPSILON {
+		return m, true
+	}
+
+	radiusA := capsuleA.GetRadius()
+	radiusB := capsuleB.GetRadius()
+	points := make([]contact.Point, 0, 2)
+	for k, t := range [2]float64{low, high} {
+		pointA := bottomA.Add(*dA.Mul(t))
+		pointB := closestPointOnSegment(*pointA, bottomB, topB)
+
+		depth := radiusA + radiusB - pointB.Sub(*pointA).Dot(c.Normal)
+		if depth < 0 {
+			continue
+		}
+		points = append(points, contact.Point{
+			PointA:  *pointA.Add(*c.Normal.Mul(radiusA)),
+			PointB:  *pointB.Sub(*c.Normal.Mul(radiusB)),
+			Depth:   depth,
+			Feature: 1 + k,
+		})
+	}
+	if len(points) > 0 {
+		m.Points = points
+	}
+
+	return m, true
+}
+
+// сфера - это капсула с отрезком нулевой длины
+func capsuleSphereContact(capsule *objects.Capsule, sphere *objects.Sphere) (*contact.Contact, bool) {
+	center, err := sphere.GetPosition()
+	if err != nil {
+		log.Printf("SAT: failed to get position of %s: %v", sphere.GetId(), err)
+		return nil, false
+	}
+
+	top, bottom := capsule.GetSegment()
+	pointA := closestPointOnSegment(*center, bottom, top)
+
+	return roundedContact(pointA, capsule.GetRadius(), *center, sphere.GetRadius())
+}
+
+// контакт двух "скруглённых" тел по ближайшим точкам их внутренних отрезков
+func roundedContact(pointA vector.Vector3D, radiusA float64, pointB vector.Vector3D, radiusB float64) (*contact.Contact, bool) {
+	delta := pointB.Sub(pointA)
+	distanceSq := delta.LengthSq()
+	sumRadii := radiusA + radiusB
+
+	if distanceSq > sumRadii*sumRadii {
+		return nil, false
+	}
+
+	distance := math.Sqrt(distanceSq)
+	normal := vector.Vector3D{X: 1, Y: 0, Z: 0}
+	if distance > SEGMENT_EPSILON {
+		normal = *delta.Mul(1 / distance)
+	}
+
+	return &contact.Contact{
+		Normal: normal,
+		Depth:  sumRadii - distance,
+		PointA: *pointA.Add(*normal.Mul(radiusA)),
+		PointB: *pointB.Sub(*normal.Mul(radiusB)),
+	}, true
+}
+
+func closestPointOnSegment(point, a, b vector.Vector3D) vector.Vector3D {
+	ab := b.Sub(a)
+	lengthSq := ab.LengthSq()
+	if lengthSq < SEGMENT_EPSILON {
+		return a
+	}
+
+	t := math.Max(0, math.Min(1, point.Sub(a).Dot(*ab)/lengthSq))
+	return *a.Add(*ab.Mul(t))
+}
+
+// ближайшие точки отрезков p1q1 и p2q2 (Ericson, Real-Time Collision Detection 5.1.9)
+func closestPointsOnSegments(p1, q1, p2, q2 vector.Vector3D) (vector.Vector3D, vector.Vector3D) {
+	d1 := q1.Sub(p1)
+	d2 := q2.Sub(p2)
+	r := p1.Sub(p2)
+
+	a := d1.LengthSq()
+	e := d2.LengthSq()
+	f := d2.Dot(*r)
+
+	var s, t float64
+
+	switch {
+	case a <= SEGMENT_EPSILON && e <= SEGMENT_EPSILON:
+		return p1, p2
+	case a <= SEGMENT_EPSILON:
+		s = 0
+		t = math.Max(0, math.Min(1, f/e))
+	default:
+		c := d1.Dot(*r)
+		if e <= SEGMENT_EPSILON {
+			t = 0
+			s = math.Max(0, math.Min(1, -c/a))
+		} else {
+			b := d1.Dot(*d2)
+			denom := a*e - b*b
+
+			// для параллельных отрезков подойдёт любое s
+			if denom != 0 {
+				s = math.Max(0, math.Min(1, (b*f-c*e)/denom))
+			}
+
+			t = (b*s + f) / e
+			if t < 0 {
+				t = 0
+				s = math.Max(0, math.Min(1, -c/a))
+			} else if t > 1 {
+				t = 1
+				s = math.Max(0, math.Min(1, (b-c)/a))
+			}
+		}
+	}
+
+	return *p1.Add(*d1.Mul(s)), *p2.Add(*d2.Mul(t))
+}
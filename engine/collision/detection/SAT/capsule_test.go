@@ -0,0 +1,139 @@
+package sat
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+// lyingCapsule returns a capsule of radius 0.5 lying along Z, it crosses the upright ones
+func lyingCapsule(id string, position vector.Vector3D) *objects.Capsule {
+	capsule := objectstest.Capsule(id, position, 0.5, 1)
+	capsule.SetOrientation(*vector.AxisAngleQuaternion(vector.Vector3D{X: 1}, math.Pi/2))
+	capsule.Update(0)
+	return capsule
+}
+
+func TestCapsuleCapsuleManifold(t *testing.T) {
+	cases := []struct {
+		name   string
+		b      *objects.Capsule
+		hit    bool
+		normal vector.Vector3D
+		depth  float64
+		// the heights of the points of A, the segments of parallel capsules touch along their common part
+		heights []float64
+	}{
+		{
+			name: "apart",
+			b:    objectstest.Capsule("b", vector.Vector3D{X: 1.1}, 0.5, 1),
+		},
+		{
+			name:    "parallel",
+			b:       objectstest.Capsule("b", vector.Vector3D{X: 0.9, Y: 0.5}, 0.5, 1),
+			hit:     true,
+			normal:  vector.Vector3D{X: 1},
+			depth:   0.1,
+			heights: []float64{-0.5, 1},
+		},
+		{
+			name:    "crossing",
+			b:       lyingCapsule("b", vector.Vector3D{X: 0.9, Y: 0.3}),
+			hit:     true,
+			normal:  vector.Vector3D{X: 1},
+			depth:   0.1,
+			heights: []float64{0.3},
+		},
+		{
+			// the caps meet end to end
+			name:    "in line",
+			b:       objectstest.Capsule("b", vector.Vector3D{Y: 2.9}, 0.5, 1),
+			hit:     true,
+			normal:  vector.Vector3D{Y: 1},
+			depth:   0.1,
+			heights: []float64{1.5},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := objectstest.Capsule("a", vector.Vector3D{}, 0.5, 1)
+
+			m, hit := capsuleCapsuleManifold(a, tc.b)
+			if hit != tc.hit {
+				t.Fatalf("hit = %v, want %v", hit, tc.hit)
+			}
+			if !hit {
+				return
+			}
+
+			if m.Normal.Sub(tc.normal).Length() > tolerance {
+				t.Errorf("normal = %v, want %v", m.Normal, tc.normal)
+			}
+			if len(m.Points) != len(tc.heights) {
+				t.Fatalf("%d points, want %d", len(m.Points), len(tc.heights))
+			}
+			for i, p := range m.Points {
+				if math.Abs(p.Depth-tc.depth) > tolerance {
+					t.Errorf("depth = %v, want %v", p.Depth, tc.depth)
+				}
+				if math.Abs(p.PointA.Y-tc.heights[i]) > tolerance {
+					t.Errorf("point %d of A is at %v, want the height %v", i, p.PointA, tc.heights[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCapsuleSphereContact(t *testing.T) {
+	cases := []struct {
+		name   string
+		center vector.Vector3D
+		hit    bool
+		normal vector.Vector3D
+		depth  float64
+	}{
+		{
+			name:   "apart",
+			center: vector.Vector3D{X: 1.1},
+		},
+		{
+			name:   "side",
+			center: vector.Vector3D{X: 0.9, Y: 0.7},
+			hit:    true,
+			normal: vector.Vector3D{X: 1},
+			depth:  0.1,
+		},
+		{
+			name:   "cap",
+			center: vector.Vector3D{Y: -1.8},
+			hit:    true,
+			normal: vector.Vector3D{Y: -1},
+			depth:  0.2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			capsule := objectstest.Capsule("capsule", vector.Vector3D{}, 0.5, 1)
+			sphere := objectstest.Sphere("sphere", tc.center, 0.5)
+
+			c, hit := capsuleSphereContact(capsule, sphere)
+			if hit != tc.hit {
+				t.Fatalf("hit = %v, want %v", hit, tc.hit)
+			}
+			if !hit {
+				return
+			}
+
+			if c.Normal.Sub(tc.normal).Length() > tolerance {
+				t.Errorf("normal = %v, want %v", c.Normal, tc.normal)
+			}
+			if math.Abs(c.Depth-tc.depth) > tolerance {
+				t.Errorf("depth = %v, want %v", c.Depth, tc.depth)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package sat
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+)
+
+// наклон направлений поиска опорных точек от -нормали плоскости
+const PLANE_TILT = 0.5
+
+// SATPlane проверяет любое выпуклое тело против статической плоскости.
+// Плоскость - это одна разделяющая ось, точки контакта - опорные точки тела, ушедшие за неё
+func SATPlane(objectID, planeID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	obj := (*objectPool)[objectID]
+
+	objB := (*objectPool)[planeID]
+	plane, ok := objB.(*objects.Plane)
+	if !ok {
+		log.Panicf("Object %d (ID: %s) is not a Plane", planeID, objB.GetId())
+		return
+	}
+
+	// две плоскости не сталкиваются, обе статические
+	if _, isPlane := obj.(*objects.Plane); isPlane {
+		return
+	}
+
+	m, hit := planeManifold(obj, plane)
+	if !hit {
+		return
+	}
+
+	m.AID = objectID
+	m.BID = planeID
+	resolving.ResolveManifold(m, objectPool, resolveAlgorithm)
+}
+
+// planeManifold добавляет к самой глубокой точке опорные точки по направлениям, наклонённым
+// от -нормали плоскости в 8 сторон: так находятся все углы грани коробки и край торца цилиндра.
+// У капсулы точки - низ обоих концов отрезка, у сферы точка одна
+func planeManifold(obj objects.Object, plane *objects.Plane) (*contact.Manifold, bool) {
+	c, hit := planeContact(obj, plane)
+	if !hit {
+		return nil, false
+	}
+	deepest := c.Manifold().Points[0]
+	deepest.Feature = 1
+
+	normal := plane.GetNormal()
+	offset := plane.GetOffset()
+
+	candidates := make([]vector.Vector3D, 0, 8)
+	switch body := obj.(type) {
+	case *objects.Sphere:
+	case *objects.Capsule:
+		top, bottom := body.GetSegment()
+		for _, end := range [2]vector.Vector3D{bottom, top} {
+			candidates = append(candidates, *end.Sub(*normal.Mul(body.GetRadius())))
+		}
+	default:
+		t1, t2 := c.Tangents()
+		for _, dir := range [8][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {-1, 1}, {-1, -1}, {1, -1}} {
+			tilt := t1.Mul(dir[0]).Add(*t2.Mul(dir[1]))
+			direction := normal.Negate().Add(*tilt.Mul(PLANE_TILT))
+			candidates = append(candidates, *obj.Support(*direction))
+		}
+	}
+
+	points := make([]contact.Point, 0, len(candidates)+1)
+	add := func(p contact.Point) {
+		for _, other := range points {
+			if other.PointA.Sub(p.PointA).LengthSq() < contact.MERGE_DISTANCE*contact.MERGE_DISTANCE {
+				return
+			}
+		}
+		points = append(points, p)
+	}
+
+	for k, point := range candidates {
+		depth := offset - normal.Dot(point)
+		if depth < 0 {
+			continue
+		}
+		add(contact.Point{
+			PointA:  point,
+			PointB:  *point.Add(*normal.Mul(depth)),
+			Depth:   depth,
+			Feature: 2 + k,
+		})
+	}
+	// самая глубокая точка последняя, иначе её feature менялся бы вместе с самым глубоким углом
+	add(deepest)
+
+	m := &contact.Manifold{Normal: c.Normal, Points: points}
+	m.Reduce()
+
+	return m, true
+}
+
+// нормаль контакта направлена от тела в плоскость, то есть против нормали плоскости
+func planeContact(obj objects.Object, plane *objects.Plane) (*contact.Contact, bool) {
+	normal := plane.GetNormal()
+
+	deepest := obj.Support(*normal.Negate())
+	depth := plane.GetOffset() - normal.Dot(*deepest)
+	if depth < 0 {
+		return nil, false
+	}
+
+	return &contact.Contact{
+		Normal: *normal.Negate(),
+		Depth:  depth,
+		PointA: *deepest,
+		PointB: *deepest.Add(*normal.Mul(depth)),
+	}, true
+}
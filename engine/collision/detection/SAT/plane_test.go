@@ -0,0 +1,84 @@
+package sat
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+func TestPlaneManifold(t *testing.T) {
+	unit := vector.Vector3D{X: 1, Y: 1, Z: 1}
+
+	cases := []struct {
+		name   string
+		obj    objects.Object
+		hit    bool
+		depth  float64
+		points int
+	}{
+		{
+			name: "sphere above",
+			obj:  objectstest.Sphere("sphere", vector.Vector3D{Y: 1.1}, 1),
+		},
+		{
+			name:   "sphere",
+			obj:    objectstest.Sphere("sphere", vector.Vector3D{X: 3, Y: 0.9, Z: -2}, 1),
+			hit:    true,
+			depth:  0.1,
+			points: 1,
+		},
+		{
+			name: "box above",
+			obj:  objectstest.Box("box", vector.Vector3D{Y: 1.1}, unit),
+		},
+		{
+			// every corner of the bottom face is in the plane
+			name:   "box",
+			obj:    objectstest.Box("box", vector.Vector3D{X: -2, Y: 0.95, Z: 1}, unit),
+			hit:    true,
+			depth:  0.05,
+			points: 4,
+		},
+		{
+			// both ends of the segment touch
+			name:   "lying capsule",
+			obj:    lyingCapsule("capsule", vector.Vector3D{Y: 0.45}),
+			hit:    true,
+			depth:  0.05,
+			points: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			plane := objects.NewPlane(vector.Vector3D{Y: 1}, vector.Vector3D{}, "floor")
+
+			m, hit := planeManifold(tc.obj, &plane)
+			if hit != tc.hit {
+				t.Fatalf("hit = %v, want %v", hit, tc.hit)
+			}
+			if !hit {
+				return
+			}
+
+			// the normal points from the body into the plane
+			if m.Normal.Sub(vector.Vector3D{Y: -1}).Length() > tolerance {
+				t.Errorf("normal = %v, want -Y", m.Normal)
+			}
+			if len(m.Points) != tc.points {
+				t.Fatalf("%d points, want %d", len(m.Points), tc.points)
+			}
+			for _, p := range m.Points {
+				if math.Abs(p.Depth-tc.depth) > tolerance {
+					t.Errorf("depth = %v, want %v", p.Depth, tc.depth)
+				}
+				// the point of B is on the plane, below the point of A
+				if math.Abs(p.PointB.Y) > tolerance || math.Abs(p.PointA.Y+tc.depth) > tolerance {
+					t.Errorf("points %v and %v are not %v below and on the plane", p.PointA, p.PointB, tc.depth)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,10 @@
 package SaP
 
 import (
-	sat "BachelorThesis/engine/collision/detection/SAT"
-	tgs "BachelorThesis/engine/collision/resolving/TGS"
+	"BachelorThesis/engine/collision/detection"
 	"BachelorThesis/engine/constants"
 	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/profiling"
 	"log"
 	"math"
 	"runtime"
@@ -16,13 +16,17 @@ type intPair struct {
 	b int
 }
 
+// Collision sorts and sweeps the swept bounding boxes, so a CCD body meets everything
+// it passed during the frame. For other bodies it is their usual bounding box
 func Collision(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
 	// First step: sort
+	sortStart := profiling.Start()
 	if constants.AlgoType == constants.N {
 		quickSort(objectPool)
 	} else {
 		radixSort(objectPool)
 	}
+	profiling.Stop(profiling.SORT, sortStart)
 
 	if !isSorted(*objectPool) {
 		log.Panicf("Objects are not sorted OLOLO")
@@ -42,13 +46,14 @@ func sapNoParallel(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgo
 	if len(*objectPool) <= 1 {
 		return
 	}
+	sweepStart := profiling.Start()
 
 	activeObjects := make(map[int]*objects.Object, 0)
 	pairs := make([]intPair, 0)
 	toDel := make([]int, len(*objectPool))
 
 	for a, obj := range *objectPool {
-		bb, err := obj.GetBoundingBox()
+		bb, err := obj.GetSweptBoundingBox()
 		if err != nil {
 			log.Printf("Warning: failed to get bounding box for object %s: %v", obj.GetId(), err)
 			continue
@@ -57,49 +62,16 @@ func sapNoParallel(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgo
 		toDel = toDel[:0]
 
 		for b, activeObj := range activeObjects {
-			activeBB, err := (*activeObj).GetBoundingBox()
+			activeBB, err := (*activeObj).GetSweptBoundingBox()
 			if err != nil {
 				log.Printf("Warning: failed to get bounding box for object %s: %v", (*activeObj).GetId(), err)
 				continue
 			}
 
-			if bb.Min.X < activeBB.Max.X {
+			if bb.Min.X <= activeBB.Max.X {
 				if checkOverlapYZ(&obj, activeObj) {
 					if constants.Pipeline == constants.ParallelPipeline {
-						switch secondaryAlgorithm {
-						case constants.SAT:
-							switch constants.SecondaryAlgoType {
-							case constants.N:
-								sat.SATNoParallel(a, b, objectPool, resolveAlgorithm)
-							case constants.PT:
-								sat.SATTrivialParallel(a, b, objectPool, resolveAlgorithm)
-							default:
-								log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
-							}
-
-						// if there is no secondary algorithm
-						case constants.NoAlgo:
-							switch resolveAlgorithm {
-							case constants.PGS:
-								switch constants.ResolveAlgoType {
-								case constants.N:
-									tgs.TGSNoParallel(a, b, objectPool)
-								case constants.PNT:
-									// TODO
-									//pgs.PGSParallelNonTrivial(a, b, objectPool)
-								default:
-									log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
-								}
-
-							// if there is no resolve algorithm just return
-							case constants.NoAlgo:
-								return
-							default:
-								log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
-							}
-						default:
-							log.Panicf("Unknown secondary algorithm: %s", secondaryAlgorithm)
-						}
+						detection.ProcessPair(a, b, objectPool, secondaryAlgorithm, resolveAlgorithm)
 					} else {
 						pairs = append(pairs, intPair{a: a, b: b})
 					}
@@ -115,51 +87,17 @@ func sapNoParallel(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgo
 
 		activeObjects[a] = &(*objectPool)[a]
 	}
+	profiling.Stop(profiling.SWEEP, sweepStart)
 
 	if constants.Pipeline == constants.ParallelPipeline {
 		return
 	}
 
-	switch secondaryAlgorithm {
-	case constants.SAT:
-		switch constants.SecondaryAlgoType {
-		case constants.N:
-			for _, pair := range pairs {
-				sat.SATNoParallel(pair.a, pair.b, objectPool, resolveAlgorithm)
-			}
-		case constants.PT:
-			for _, pair := range pairs {
-				sat.SATTrivialParallel(pair.a, pair.b, objectPool, resolveAlgorithm)
-			}
-		default:
-			log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
-		}
-
-	// if there is no secondary algorithm
-	case constants.NoAlgo:
-		switch resolveAlgorithm {
-		case constants.PGS:
-			switch constants.ResolveAlgoType {
-			case constants.N:
-				for _, pair := range pairs {
-					tgs.TGSNoParallel(pair.a, pair.b, objectPool)
-				}
-			case constants.PNT:
-				// TODO
-				//pgs.PGSParallelNonTrivial(a, b, objectPool)
-			default:
-				log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
-			}
-
-		// if there is no resolve algorithm just return
-		case constants.NoAlgo:
-			return
-		default:
-			log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
-		}
-	default:
-		log.Panicf("Unknown secondary algorithm: %s", secondaryAlgorithm)
+	narrowStart := profiling.Start()
+	for _, pair := range pairs {
+		detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
 	}
+	profiling.Stop(profiling.NARROW, narrowStart)
 }
 
 // --- Parallel Non Trivial algorithm ---
@@ -168,11 +106,14 @@ func sapParallelNonTrivial(objectPool *[]objects.Object, secondaryAlgorithm, res
 	if len(*objectPool) <= 1 {
 		return
 	}
+	sweepStart := profiling.Start()
 
 	workersCount := runtime.NumCPU() - 1
 	if workersCount < 3 {
 		log.Printf("Warning: number of workers is less than 3: %d. Using no parallel algorithm", workersCount)
 		sapNoParallel(objectPool, secondaryAlgorithm, resolveAlgorithm)
+		// the sequential sweep has found every pair, the parallel one would report them again
+		return
 	}
 	wg := new(sync.WaitGroup)
 	wg.Add(workersCount)
@@ -194,63 +135,13 @@ func sapParallelNonTrivial(objectPool *[]objects.Object, secondaryAlgorithm, res
 		go func(start, end int) {
 			defer wg.Done()
 
-			for i, obj := range (*objectPool)[start:end] {
-				bb, err := obj.GetBoundingBox()
-				if err != nil {
-					log.Printf("Warning: failed to get bounding box for object %s: %v", obj.GetId(), err)
-					continue
+			sweepChunk(start, end, *objectPool, func(pair intPair) {
+				if constants.Pipeline == constants.ParallelPipeline {
+					detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
+				} else {
+					outChan <- &pair
 				}
-
-				for j, activeObj := range (*objectPool)[start+i+1:] {
-					activeBB, err := activeObj.GetBoundingBox()
-					if err != nil {
-						log.Printf("Warning: failed to get bounding box for object %s: %v", activeObj.GetId(), err)
-						continue
-					}
-
-					if bb.Min.X < activeBB.Max.X {
-						if checkOverlapYZ(&obj, &activeObj) {
-							if constants.Pipeline == constants.ParallelPipeline {
-								switch secondaryAlgorithm {
-								case constants.SAT:
-									switch constants.SecondaryAlgoType {
-									case constants.N:
-										sat.SATNoParallel(start+i, start+i+j+1, objectPool, resolveAlgorithm)
-									case constants.PT:
-										sat.SATTrivialParallel(start+i, start+i+j+1, objectPool, resolveAlgorithm)
-									default:
-										log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
-									}
-								// if there is no secondary algorithm
-								case constants.NoAlgo:
-									switch resolveAlgorithm {
-									case constants.PGS:
-										switch constants.ResolveAlgoType {
-										case constants.N:
-											tgs.TGSNoParallel(start+i, start+j+1, objectPool)
-										case constants.PNT:
-											// TODO
-											//pgs.PGSParallelNonTrivial(start+i, start+j+1, objectPool)
-										default:
-											log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
-										}
-
-									// if there is no resolve algorithm just return
-									case constants.NoAlgo:
-										return
-									default:
-										log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
-									}
-								}
-							} else {
-								outChan <- &intPair{a: start + i, b: start + j + i + 1}
-							}
-						}
-					} else {
-						break
-					}
-				}
-			}
+			})
 		}(start, end)
 	}
 
@@ -258,55 +149,53 @@ func sapParallelNonTrivial(objectPool *[]objects.Object, secondaryAlgorithm, res
 	for pair := range outChan {
 		pairs = append(pairs, *pair)
 	}
+	profiling.Stop(profiling.SWEEP, sweepStart)
 
 	if constants.Pipeline == constants.ParallelPipeline {
 		return
 	}
 
-	switch secondaryAlgorithm {
-	case constants.SAT:
-		switch constants.SecondaryAlgoType {
-		case constants.N:
-			for _, pair := range pairs {
-				sat.SATNoParallel(pair.a, pair.b, objectPool, resolveAlgorithm)
-			}
-		case constants.PT:
-			for _, pair := range pairs {
-				sat.SATTrivialParallel(pair.a, pair.b, objectPool, resolveAlgorithm)
-			}
-		default:
-			log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
+	narrowStart := profiling.Start()
+	for _, pair := range pairs {
+		detection.ProcessPair(pair.a, pair.b, objectPool, secondaryAlgorithm, resolveAlgorithm)
+	}
+	profiling.Stop(profiling.NARROW, narrowStart)
+}
+
+// --- Helper function ---
+
+// sweepChunk reports every pair (a, b) with a in [start, end) and b > a whose swept boxes overlap.
+// The pool is sorted by Min.X, so the scan for a stops at the first object that starts after a ends
+func sweepChunk(start, end int, objectPool []objects.Object, found func(intPair)) {
+	for a := start; a < end; a++ {
+		obj := objectPool[a]
+		bb, err := obj.GetSweptBoundingBox()
+		if err != nil {
+			log.Printf("Warning: failed to get bounding box for object %s: %v", obj.GetId(), err)
+			continue
 		}
-	// if there is no secondary algorithm
-	case constants.NoAlgo:
-		switch resolveAlgorithm {
-		case constants.PGS:
-			switch constants.ResolveAlgoType {
-			case constants.N:
-				for _, pair := range pairs {
-					tgs.TGSNoParallel(pair.a, pair.b, objectPool)
-				}
-			case constants.PNT:
-				// TODO
-				//pgs.PGSParallelNonTrivial(start+i, start+j+1, objectPool)
-			default:
-				log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+
+		for b := a + 1; b < len(objectPool); b++ {
+			activeObj := objectPool[b]
+			activeBB, err := activeObj.GetSweptBoundingBox()
+			if err != nil {
+				log.Printf("Warning: failed to get bounding box for object %s: %v", activeObj.GetId(), err)
+				continue
 			}
 
-		// if there is no resolve algorithm just return
-		case constants.NoAlgo:
-			return
-		default:
-			log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
+			if activeBB.Min.X > bb.Max.X {
+				break
+			}
+			if checkOverlapYZ(&obj, &activeObj) {
+				found(intPair{a: a, b: b})
+			}
 		}
 	}
 }
 
-// --- Helper function ---
-
 func checkOverlapYZ(objA, objB *objects.Object) bool {
-	bbA, errA := (*objA).GetBoundingBox()
-	bbB, errB := (*objB).GetBoundingBox()
+	bbA, errA := (*objA).GetSweptBoundingBox()
+	bbB, errB := (*objB).GetSweptBoundingBox()
 
 	if errA != nil || errB != nil {
 		return false
@@ -0,0 +1,300 @@
+package SaP
+
+import (
+	"BachelorThesis/engine/collision/detection"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/profiling"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// spheresAt returns unit spheres whose bounding boxes start at the given x
+func spheresAt(xs ...float64) []objects.Object {
+	pool := make([]objects.Object, len(xs))
+	for i, x := range xs {
+		pool[i] = objectstest.Sphere(fmt.Sprint(i), vector.Vector3D{X: x + 1}, 1)
+	}
+	return pool
+}
+
+func minX(obj objects.Object) float64 {
+	bb, _ := obj.GetSweptBoundingBox()
+	return bb.Min.X
+}
+
+var sortCases = []struct {
+	name string
+	xs   []float64
+}{
+	{"empty", nil},
+	{"single", []float64{3}},
+	{"sorted", []float64{-2, -1, 0, 1, 2}},
+	{"reversed", []float64{5, 4, 3, 2, 1}},
+	{"negative", []float64{-0.5, -100, -3.25, -1e9, -1e-9}},
+	{"mixed signs", []float64{7, -7, 0.001, -0.001, 1e12, -1e12}},
+	{"signed zeros", []float64{0, math.Copysign(0, -1), 0, -1, 1}},
+	{"duplicates", []float64{2, 1, 2, 1, 2, 1, 0}},
+	{"all equal", []float64{4, 4, 4, 4}},
+	{"infinities", []float64{math.Inf(1), 0, math.Inf(-1), 5}},
+}
+
+func TestSorts(t *testing.T) {
+	sorts := []struct {
+		name string
+		sort func(*[]objects.Object)
+	}{
+		{"quickSort", quickSort},
+		{"radixSort", radixSort},
+	}
+
+	for _, s := range sorts {
+		for _, tc := range sortCases {
+			t.Run(s.name+"/"+tc.name, func(t *testing.T) {
+				pool := spheresAt(tc.xs...)
+				s.sort(&pool)
+
+				checkPermutation(t, pool, len(tc.xs))
+				if !isSorted(pool) {
+					t.Errorf("not sorted: %v", minXs(pool))
+				}
+			})
+		}
+	}
+}
+
+// radixSort sorts the bits of the keys, so NaN boxes of bodies that blew up land after every number
+// instead of breaking the comparisons
+func TestRadixSortNaN(t *testing.T) {
+	nan := math.NaN()
+	pool := spheresAt(3, nan, -2, nan, 0, math.Inf(1), -5)
+	radixSort(&pool)
+
+	checkPermutation(t, pool, 7)
+	want := []float64{-5, -2, 0, 3, math.Inf(1)}
+	for i, x := range want {
+		if got := minX(pool[i]); got != x {
+			t.Errorf("position %d: got %v, want %v (order %v)", i, got, x, minXs(pool))
+		}
+	}
+	for _, obj := range pool[len(want):] {
+		if !math.IsNaN(minX(obj)) {
+			t.Errorf("NaN boxes are not last: %v", minXs(pool))
+		}
+	}
+}
+
+// radixSort is a stable sort, equal boxes keep their order
+func TestRadixSortStable(t *testing.T) {
+	pool := spheresAt(1, 0, 1, 0, 1, 0)
+	radixSort(&pool)
+
+	want := []string{"1", "3", "5", "0", "2", "4"}
+	for i, id := range want {
+		if got := pool[i].GetId(); got != id {
+			t.Fatalf("position %d: got %s, want %s", i, got, id)
+		}
+	}
+}
+
+// the parallel counting sort only runs on large pools
+func TestSortsLarge(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	xs := make([]float64, 20000)
+	for i := range xs {
+		xs[i] = math.Round((random.Float64()-0.5)*1000) / 4
+	}
+
+	for name, sort := range map[string]func(*[]objects.Object){"quickSort": quickSort, "radixSort": radixSort} {
+		pool := spheresAt(xs...)
+		sort(&pool)
+		checkPermutation(t, pool, len(xs))
+		if !isSorted(pool) {
+			t.Errorf("%s: large pool is not sorted", name)
+		}
+	}
+}
+
+// checkPermutation makes sure the sort neither lost nor repeated an object
+func checkPermutation(t *testing.T, pool []objects.Object, n int) {
+	t.Helper()
+
+	if len(pool) != n {
+		t.Fatalf("got %d objects, want %d", len(pool), n)
+	}
+	seen := make(map[string]bool, n)
+	for _, obj := range pool {
+		if seen[obj.GetId()] {
+			t.Fatalf("object %s appears twice", obj.GetId())
+		}
+		seen[obj.GetId()] = true
+	}
+}
+
+func minXs(pool []objects.Object) []float64 {
+	xs := make([]float64, len(pool))
+	for i, obj := range pool {
+		xs[i] = minX(obj)
+	}
+	return xs
+}
+
+func TestCheckOverlapYZ(t *testing.T) {
+	half := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	cases := []struct {
+		name string
+		b    vector.Vector3D
+		want bool
+	}{
+		{"same place", vector.Vector3D{}, true},
+		{"overlap in y and z", vector.Vector3D{Y: 1.5, Z: -1.5}, true},
+		{"touching in y", vector.Vector3D{Y: 2}, true},
+		{"touching corner", vector.Vector3D{Y: 2, Z: -2}, true},
+		{"apart in y", vector.Vector3D{Y: 2.01}, false},
+		{"apart in z", vector.Vector3D{Z: -3}, false},
+		{"apart in y and z", vector.Vector3D{Y: 5, Z: 5}, false},
+		// x is the sweep axis, the sweep checks it
+		{"apart only in x", vector.Vector3D{X: 10}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := objects.Object(objectstest.Box("a", vector.Vector3D{}, half))
+			b := objects.Object(objectstest.Box("b", tc.b, half))
+
+			if got := checkOverlapYZ(&a, &b); got != tc.want {
+				t.Errorf("checkOverlapYZ = %v, want %v", got, tc.want)
+			}
+			if got := checkOverlapYZ(&b, &a); got != tc.want {
+				t.Errorf("checkOverlapYZ reversed = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("contained", func(t *testing.T) {
+		a := objects.Object(objectstest.Box("a", vector.Vector3D{}, vector.Vector3D{X: 5, Y: 5, Z: 5}))
+		b := objects.Object(objectstest.Box("b", vector.Vector3D{Y: 1}, half))
+		if !checkOverlapYZ(&a, &b) || !checkOverlapYZ(&b, &a) {
+			t.Error("a box inside another one does not overlap it")
+		}
+	})
+}
+
+// the chunks of the parallel sweep find the pairs of the sequential one, whatever the number of workers
+func TestSweepChunk(t *testing.T) {
+	touching := spheresAt(0, 2, 4.5)
+	crowded := objectstest.RandomSpheres(2048, 1)
+	quickSort(&crowded)
+
+	for name, pool := range map[string][]objects.Object{"touching in x": touching, "crowded": crowded} {
+		detection.Record()
+		sapNoParallel(&pool, constants.NoAlgo, constants.NoAlgo)
+		want := detection.Recorded()
+		if name == "touching in x" && len(want) != 1 {
+			t.Fatalf("%s: the sequential sweep found %v, want the touching pair", name, want)
+		}
+
+		for _, workers := range []int{1, 3, 7} {
+			found := make(map[detection.Candidate]int)
+			for w := 0; w < workers; w++ {
+				sweepChunk(w*len(pool)/workers, (w+1)*len(pool)/workers, pool, func(pair intPair) {
+					found[detection.NewCandidate(pool[pair.a].GetId(), pool[pair.b].GetId())]++
+				})
+			}
+
+			if len(found) != len(want) {
+				t.Errorf("%s, %d workers: %d pairs, want %d", name, workers, len(found), len(want))
+			}
+			for _, pair := range want {
+				if found[pair] != 1 {
+					t.Errorf("%s, %d workers: pair %v found %d times", name, workers, pair, found[pair])
+				}
+			}
+		}
+	}
+}
+
+// the stages are disjoint spans of the frame, in the parallel pipeline the narrow phase is a part of the sweep
+func TestStageTimings(t *testing.T) {
+	defer func(pipeline string) { constants.Pipeline = pipeline }(constants.Pipeline)
+	profiling.Enable(true)
+	defer profiling.Enable(false)
+
+	for _, pipeline := range []string{constants.SequentialPipeline, constants.ParallelPipeline} {
+		t.Run(pipeline, func(t *testing.T) {
+			constants.Pipeline = pipeline
+			pool := objectstest.RandomSpheres(4096, 1)
+			profiling.Take()
+
+			start := time.Now()
+			Collision(&pool, constants.NoAlgo, constants.NoAlgo)
+			elapsed := time.Since(start)
+			timings := profiling.Take()
+
+			if total := timings[profiling.SORT] + timings[profiling.SWEEP] + timings[profiling.NARROW]; total > elapsed {
+				t.Errorf("stages %v add up to %v, longer than the %v of the whole call", timings, total, elapsed)
+			}
+			if narrow := timings[profiling.NARROW]; (pipeline == constants.ParallelPipeline) != (narrow == 0) {
+				t.Errorf("narrow phase %v", narrow)
+			}
+		})
+	}
+}
+
+func benchmarkSort(b *testing.B, sort func(*[]objects.Object)) {
+	for _, n := range objectstest.Sizes {
+		b.Run(objectstest.SizeName(n), func(b *testing.B) {
+			scene := objectstest.RandomSpheres(n, 1)
+			pool := make([]objects.Object, n)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(pool, scene)
+				b.StartTimer()
+
+				sort(&pool)
+			}
+		})
+	}
+}
+
+func BenchmarkQuickSort(b *testing.B) {
+	benchmarkSort(b, quickSort)
+}
+
+func BenchmarkRadixSort(b *testing.B) {
+	benchmarkSort(b, radixSort)
+}
+
+// the sweep alone: without narrow phase and resolver the pairs cost nothing
+func benchmarkSweep(b *testing.B, sweep func(*[]objects.Object, string, string)) {
+	for _, n := range objectstest.Sizes {
+		b.Run(objectstest.SizeName(n), func(b *testing.B) {
+			pool := objectstest.RandomSpheres(n, 1)
+			quickSort(&pool)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sweep(&pool, constants.NoAlgo, constants.NoAlgo)
+			}
+		})
+	}
+}
+
+func BenchmarkSweepNoParallel(b *testing.B) {
+	benchmarkSweep(b, sapNoParallel)
+}
+
+func BenchmarkSweepParallelNonTrivial(b *testing.B) {
+	if runtime.NumCPU() < 4 {
+		b.Skip("the non trivial parallel sweep needs at least 3 workers")
+	}
+	benchmarkSweep(b, sapParallelNonTrivial)
+}
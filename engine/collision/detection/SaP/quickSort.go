@@ -21,13 +21,13 @@ func quickSortRecursive(objects []objects.Object, low, high int) {
 }
 
 func partition(objects []objects.Object, low, high int) int {
-	pivotBB, _ := objects[high].GetBoundingBox()
+	pivotBB, _ := objects[high].GetSweptBoundingBox()
 	pivotValue := pivotBB.Min.X
 
 	i := low - 1
 
 	for j := low; j < high; j++ {
-		currentBB, _ := objects[j].GetBoundingBox()
+		currentBB, _ := objects[j].GetSweptBoundingBox()
 		if currentBB.Min.X <= pivotValue {
 			i++
 			objects[i], objects[j] = objects[j], objects[i]
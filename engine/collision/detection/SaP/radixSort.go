@@ -138,7 +138,7 @@ func radixSort(objects *[]objects.Object) {
 			}
 
 			for i := start; i < end; i++ {
-				bb, _ := (*objects)[i].GetBoundingBox()
+				bb, _ := (*objects)[i].GetSweptBoundingBox()
 				items[i] = sortItem{
 					obj: (*objects)[i],
 					key: floatToSortableUint64(bb.Min.X),
@@ -172,8 +172,8 @@ func radixSort(objects *[]objects.Object) {
 
 func isSorted(objects []objects.Object) bool {
 	for i := 1; i < len(objects); i++ {
-		bb1, _ := objects[i-1].GetBoundingBox()
-		bb2, _ := objects[i].GetBoundingBox()
+		bb1, _ := objects[i-1].GetSweptBoundingBox()
+		bb2, _ := objects[i].GetSweptBoundingBox()
 		if bb1.Min.X > bb2.Min.X {
 			return false
 		}
@@ -0,0 +1,50 @@
+package detection
+
+import (
+	"BachelorThesis/engine/objects"
+	"sync"
+)
+
+// Candidate is a broad phase pair named by the ids of its objects, the smaller id first.
+// Indices can't be compared between broad phases, SaP reorders the pool
+type Candidate struct {
+	A string
+	B string
+}
+
+func NewCandidate(idA, idB string) Candidate {
+	if idB < idA {
+		idA, idB = idB, idA
+	}
+	return Candidate{A: idA, B: idB}
+}
+
+// while recording ProcessPair only collects the pairs. The flag is set before the broad phase
+// starts its workers and cleared after they are done, so they read it without the lock
+var (
+	recording  bool
+	recorded   []Candidate
+	recordedMu sync.Mutex
+)
+
+// Record makes ProcessPair collect the pairs instead of testing them, until Recorded is called
+func Record() {
+	recorded = make([]Candidate, 0)
+	recording = true
+}
+
+// Recorded stops the recording and returns the pairs ProcessPair got since Record, repeated ones included
+func Recorded() []Candidate {
+	recording = false
+	pairs := recorded
+	recorded = nil
+	return pairs
+}
+
+func record(aID, bID int, objectPool *[]objects.Object) {
+	pair := NewCandidate((*objectPool)[aID].GetId(), (*objectPool)[bID].GetId())
+
+	recordedMu.Lock()
+	recorded = append(recorded, pair)
+	recordedMu.Unlock()
+}
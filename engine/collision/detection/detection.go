@@ -0,0 +1,120 @@
+package detection
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	ccd "BachelorThesis/engine/collision/detection/CCD"
+	epa "BachelorThesis/engine/collision/detection/EPA"
+	gjk "BachelorThesis/engine/collision/detection/GJK"
+	sat "BachelorThesis/engine/collision/detection/SAT"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"log"
+)
+
+// ProcessPair passes a broad phase candidate pair to the chosen secondary algorithm,
+// or straight to the resolver if there is no secondary algorithm.
+// Pairs with a CCD body that meet during the frame are kept for ResolveImpacts.
+// It is not timed, the broad phase times the loop over its pairs as a whole
+func ProcessPair(aID, bID int, objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
+	if recording {
+		record(aID, bID, objectPool)
+		return
+	}
+
+	objA := (*objectPool)[aID]
+	objB := (*objectPool)[bID]
+	if objA.GetCCD() || objB.GetCCD() {
+		if t, hit := ccd.TimeOfImpact(objA, objB); hit {
+			ccd.AddImpact(aID, bID, t)
+			return
+		}
+	}
+
+	processDiscrete(aID, bID, objectPool, secondaryAlgorithm, resolveAlgorithm)
+}
+
+// ResolveImpacts rewinds the CCD bodies to their first impacts of the frame and resolves
+// the contacts there. It runs when the broad phase has found every pair
+func ResolveImpacts(objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
+	impacts := ccd.Take()
+	if len(impacts) == 0 {
+		return
+	}
+
+	ccd.Rewind(impacts, objectPool)
+
+	for _, impact := range impacts {
+		c, ok := ccd.ImpactContact(impact.AID, impact.BID, objectPool)
+		if !ok {
+			// the bodies overlap after the rewind, the usual test finds the contact
+			processDiscrete(impact.AID, impact.BID, objectPool, secondaryAlgorithm, resolveAlgorithm)
+			continue
+		}
+
+		resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
+	}
+}
+
+// processDiscrete tests the pair where the bodies are now
+func processDiscrete(aID, bID int, objectPool *[]objects.Object, secondaryAlgorithm, resolveAlgorithm string) {
+	switch secondaryAlgorithm {
+	case constants.SAT:
+		switch constants.SecondaryAlgoType {
+		case constants.N:
+			sat.SATNoParallel(aID, bID, objectPool, resolveAlgorithm)
+		case constants.PT:
+			sat.SATTrivialParallel(aID, bID, objectPool, resolveAlgorithm)
+		default:
+			log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
+		}
+
+	case constants.GJK:
+		switch constants.SecondaryAlgoType {
+		case constants.N:
+			gjkNoParallel(aID, bID, objectPool, resolveAlgorithm)
+		default:
+			log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
+		}
+
+	case constants.EPA:
+		switch constants.SecondaryAlgoType {
+		case constants.N:
+			epa.EPANoParallel(aID, bID, objectPool, resolveAlgorithm)
+		default:
+			log.Panicf("Unknown secondary algorithm type: %s", constants.SecondaryAlgoType)
+		}
+
+	// if there is no secondary algorithm
+	case constants.NoAlgo:
+		resolving.Resolve(aID, bID, objectPool, resolveAlgorithm)
+	default:
+		log.Panicf("Unknown secondary algorithm: %s", secondaryAlgorithm)
+	}
+}
+
+// gjkNoParallel tests the pair with GJK and resolves the contact of an intersecting pair.
+// Two spheres get their exact contact, any other pair gets the one EPA finds from the simplex
+func gjkNoParallel(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	objA := (*objectPool)[aID]
+	objB := (*objectPool)[bID]
+
+	result := gjk.Query(objA, objB)
+	if !result.Intersect {
+		return
+	}
+
+	_, sphereA := objA.(*objects.Sphere)
+	_, sphereB := objB.(*objects.Sphere)
+	if sphereA && sphereB {
+		if c, ok := contact.SphereContact(aID, bID, objectPool); ok {
+			resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
+		}
+		return
+	}
+
+	c := epa.Penetration(objA, objB, result)
+	c.AID = aID
+	c.BID = bID
+	resolving.ResolveManifold(c.Manifold(), objectPool, resolveAlgorithm)
+}
@@ -0,0 +1,49 @@
+package detection
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"testing"
+)
+
+// EPA converges on curved shapes only up to its tolerance
+const tolerance = 1e-4
+
+// the GJK narrow phase hands the pairs that are not two spheres to the resolver too
+func TestGJKResolvesEveryShape(t *testing.T) {
+	unit := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	cases := []struct {
+		name string
+		a, b objects.Object
+	}{
+		{"box and box", objectstest.Box("box_a", vector.Vector3D{}, unit), objectstest.Box("box_b", vector.Vector3D{X: 1.9}, unit)},
+		{"box and sphere", objectstest.Box("box_c", vector.Vector3D{}, unit), objectstest.Sphere("sphere_c", vector.Vector3D{X: 1.9}, 1)},
+		{"capsule and box", objectstest.Capsule("capsule_d", vector.Vector3D{X: 1.4}, 0.5, 1), objectstest.Box("box_d", vector.Vector3D{}, unit)},
+		{"sphere and sphere", objectstest.Sphere("sphere_e", vector.Vector3D{}, 1), objectstest.Sphere("sphere_f", vector.Vector3D{X: 1.9}, 1)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// A moves towards B, which is to the right of it
+			positionA, _ := tc.a.GetPosition()
+			positionB, _ := tc.b.GetPosition()
+			approach := *positionB.Sub(*positionA).Normalize()
+			tc.a.ApplyVelocity(approach)
+			pool := []objects.Object{tc.a, tc.b}
+
+			contact.NextFrame()
+			processDiscrete(0, 1, &pool, constants.GJK, constants.PGS)
+			resolving.Solve(&pool, constants.PGS)
+
+			velA, _ := tc.a.GetVelocity()
+			velB, _ := tc.b.GetVelocity()
+			if relative := velB.Sub(*velA).Dot(approach); relative < -tolerance {
+				t.Errorf("the pair still approaches at %v, it was not resolved", -relative)
+			}
+		})
+	}
+}
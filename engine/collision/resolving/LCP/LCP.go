@@ -0,0 +1,241 @@
+package lcp
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+)
+
+const (
+	// directions of the friction pyramid: +t1, -t1, +t2, -t2
+	FRICTION_DIRECTIONS = 4
+	// the matrix grows as the square of the contacts, bigger islands are solved iteratively
+	LCP_MAX_CONTACTS = 128
+	// iterations for the islands that are too big
+	FALLBACK_ITERATIONS = 20
+	// added to the diagonal, the points of a manifold are redundant and make the matrix singular
+	REGULARIZATION = 1e-7
+)
+
+// contacts of the current step
+var gathered constraint.Buffer
+
+// AddContact stores the contact until the end of the step, the system is solved by LCPNoParallel
+func AddContact(c *contact.Contact) {
+	gathered.Add(c)
+}
+
+// AddPair is AddContact for a pair without a narrow phase, only spheres are supported
+func AddPair(aID, bID int, objectPool *[]objects.Object) {
+	gathered.AddPair(aID, bID, objectPool)
+}
+
+// LCPNoParallel solves the contacts of the step exactly, island by island
+func LCPNoParallel(objectPool *[]objects.Object) {
+	bodies, contacts := buildSystem(gathered.Take(), objectPool)
+	if len(contacts) == 0 {
+		return
+	}
+
+	solveIslands(bodies, contacts)
+
+	constraint.WriteBack(bodies)
+}
+
+func buildSystem(gathered []contact.Contact, objectPool *[]objects.Object) ([]constraint.Body, []constraint.Contact) {
+	bodies, contacts := constraint.Build(gathered, objectPool)
+	// the same stabilization as PGS, so the exact solution can be compared with it
+	constraint.SetTargets(contacts, constraint.BAUMGARTE_BIAS)
+
+	return bodies, contacts
+}
+
+func solveIslands(bodies []constraint.Body, contacts []constraint.Contact) {
+	for _, island := range findIslands(bodies, contacts) {
+		if len(island) > LCP_MAX_CONTACTS {
+			log.Printf("LCP: island of %d contacts is too big, solving it iteratively", len(island))
+			solveIteratively(island, bodies, contacts)
+			continue
+		}
+
+		if err := solveIsland(island, bodies, contacts); err != nil {
+			log.Printf("LCP: %v, solving the island of %d contacts iteratively", err, len(island))
+			solveIteratively(island, bodies, contacts)
+		}
+	}
+}
+
+// findIslands groups the contacts connected through dynamic bodies,
+// static bodies don't connect anything because nothing moves them
+func findIslands(bodies []constraint.Body, contacts []constraint.Contact) [][]int {
+	parent := make([]int, len(bodies))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for _, c := range contacts {
+		if bodies[c.A].InvMass > 0 && bodies[c.B].InvMass > 0 {
+			parent[find(c.A)] = find(c.B)
+		}
+	}
+
+	islands := make([][]int, 0)
+	// root body -> island index
+	byRoot := make(map[int]int)
+	for i, c := range contacts {
+		body := c.A
+		if bodies[body].InvMass == 0 {
+			body = c.B
+		}
+
+		root := find(body)
+		index, ok := byRoot[root]
+		if !ok {
+			index = len(islands)
+			byRoot[root] = index
+			islands = append(islands, nil)
+		}
+		islands[index] = append(islands[index], i)
+	}
+
+	return islands
+}
+
+// solveIsland builds the LCP of the island and applies the impulses it returns.
+// Every contact has a normal impulse, the impulses along the friction pyramid directions and
+// the sliding speed, which keeps the friction on the pyramid when the contact slides:
+//
+//	0 <= lambda ⊥ J_n v - target >= 0
+//	0 <= beta   ⊥ D v + E gamma >= 0
+//	0 <= gamma  ⊥ mu lambda - E^T beta >= 0
+//
+// where v is the velocity after the impulses. The pyramid uses the static coefficient,
+// a single coefficient is all the model has
+func solveIsland(island []int, bodies []constraint.Body, contacts []constraint.Contact) error {
+	m := len(island)
+	rowsPerContact := 1 + FRICTION_DIRECTIONS + 1
+	n := m * rowsPerContact
+
+	directions := make([][FRICTION_DIRECTIONS + 1]vector.Vector3D, m)
+	for k, c := range island {
+		con := &contacts[c]
+		directions[k] = [FRICTION_DIRECTIONS + 1]vector.Vector3D{
+			con.Normal,
+			con.Tangents[0], *con.Tangents[0].Negate(),
+			con.Tangents[1], *con.Tangents[1].Negate(),
+		}
+	}
+
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+	}
+	q := make([]float64, n)
+
+	for k1, c1 := range island {
+		con1 := &contacts[c1]
+		base1 := k1 * rowsPerContact
+
+		for d1 := 0; d1 <= FRICTION_DIRECTIONS; d1++ {
+			row := base1 + d1
+
+			// velocity part: the response of direction d1 of contact 1 to a unit impulse along d2 of contact 2
+			for k2, c2 := range island {
+				con2 := &contacts[c2]
+				base2 := k2 * rowsPerContact
+				for d2 := 0; d2 <= FRICTION_DIRECTIONS; d2++ {
+					matrix[row][base2+d2] = response(con1, directions[k1][d1], con2, directions[k2][d2], bodies)
+				}
+			}
+			matrix[row][row] += REGULARIZATION
+
+			q[row] = con1.RelativeVelocity(bodies).Dot(directions[k1][d1])
+		}
+		q[base1] -= con1.Target
+
+		// the sliding speed couples the friction directions (E) with the friction cone (mu, -E^T)
+		slack := base1 + rowsPerContact - 1
+		for d := 1; d <= FRICTION_DIRECTIONS; d++ {
+			matrix[base1+d][slack] = 1
+			matrix[slack][base1+d] = -1
+		}
+		matrix[slack][base1] = con1.Material.StaticFriction
+	}
+
+	z, err := lemke(matrix, q)
+	if err != nil {
+		return err
+	}
+
+	for k, c := range island {
+		con := &contacts[c]
+		base := k * rowsPerContact
+
+		impulse := vector.Vector3D{}
+		for d := 0; d <= FRICTION_DIRECTIONS; d++ {
+			impulse = *impulse.Add(*directions[k][d].Mul(z[base+d]))
+		}
+		con.ApplyImpulse(impulse, bodies)
+
+		con.NormalImpulse = z[base]
+		con.TangentImpulse = [2]float64{z[base+1] - z[base+2], z[base+3] - z[base+4]}
+	}
+
+	return nil
+}
+
+// contactEnd is one of the two bodies of a contact,
+// the impulse pushes B along the direction (sign 1) and A against it (sign -1)
+type contactEnd struct {
+	body int
+	arm  vector.Vector3D
+	sign float64
+}
+
+func ends(c *constraint.Contact) [2]contactEnd {
+	return [2]contactEnd{{c.A, c.RA, -1}, {c.B, c.RB, 1}}
+}
+
+// response is the change of the relative velocity of contact 1 along direction 1
+// when a unit impulse is applied at contact 2 along direction 2. Only shared dynamic bodies count
+func response(c1 *constraint.Contact, d1 vector.Vector3D, c2 *constraint.Contact, d2 vector.Vector3D, bodies []constraint.Body) float64 {
+	result := 0.0
+
+	for _, e1 := range ends(c1) {
+		body := &bodies[e1.body]
+		if body.InvMass == 0 {
+			continue
+		}
+
+		for _, e2 := range ends(c2) {
+			if e1.body != e2.body {
+				continue
+			}
+
+			angular := e1.arm.Cross(d1).Dot(*body.InvInertia.MulVector(*e2.arm.Cross(d2)))
+			result += e1.sign * e2.sign * (body.InvMass*d1.Dot(d2) + angular)
+		}
+	}
+
+	return result
+}
+
+// solveIteratively is the fallback for the islands Lemke can't handle
+func solveIteratively(island []int, bodies []constraint.Body, contacts []constraint.Contact) {
+	for i := 0; i < FALLBACK_ITERATIONS; i++ {
+		for _, c := range island {
+			contacts[c].SolveNormal(contacts[c].Target, bodies)
+			contacts[c].SolveFriction(bodies)
+		}
+	}
+}
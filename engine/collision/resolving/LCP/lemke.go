@@ -0,0 +1,150 @@
+package lcp
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	LEMKE_EPSILON = 1e-12
+	// degenerate problems (several contacts on one face) can end with a wrong basis,
+	// the answer is checked against the problem with that tolerance
+	LEMKE_TOLERANCE = 1e-6
+	// more pivots than that means the algorithm is cycling
+	LEMKE_MAX_PIVOTS_FACTOR = 50
+)
+
+// lemke solves the linear complementarity problem
+//
+//	w = M z + q,  w >= 0,  z >= 0,  w * z = 0
+//
+// with Lemke's complementary pivoting and returns z
+func lemke(m [][]float64, q []float64) ([]float64, error) {
+	n := len(q)
+	z := make([]float64, n)
+
+	// the trivial solution z = 0 works when q is not negative
+	start := 0
+	for i := 1; i < n; i++ {
+		if q[i] < q[start] {
+			start = i
+		}
+	}
+	if n == 0 || q[start] >= 0 {
+		return z, nil
+	}
+
+	// tableau of I w - M z - e z0 = q, variables are w (0..n-1), z (n..2n-1) and z0 (2n),
+	// the last column is the right hand side
+	artificial := 2 * n
+	rhs := 2*n + 1
+	tableau := make([][]float64, n)
+	basis := make([]int, n)
+	for i := range tableau {
+		row := make([]float64, 2*n+2)
+		row[i] = 1
+		for j := 0; j < n; j++ {
+			row[n+j] = -m[i][j]
+		}
+		row[artificial] = -1
+		row[rhs] = q[i]
+
+		tableau[i] = row
+		basis[i] = i
+	}
+
+	// z0 enters at the most negative q, which makes every right hand side non negative
+	leaving := pivot(tableau, basis, start, artificial)
+
+	for iteration := 0; iteration < LEMKE_MAX_PIVOTS_FACTOR*n; iteration++ {
+		entering := complement(leaving, n)
+
+		row := ratioTest(tableau, basis, entering, artificial)
+		if row < 0 {
+			return nil, fmt.Errorf("lemke: ray termination after %d pivots", iteration)
+		}
+
+		leaving = pivot(tableau, basis, row, entering)
+		if leaving == artificial {
+			for i, variable := range basis {
+				if variable >= n && variable < 2*n {
+					z[variable-n] = math.Max(tableau[i][rhs], 0)
+				}
+			}
+			if residual := complementarityError(m, q, z); residual > LEMKE_TOLERANCE {
+				return nil, fmt.Errorf("lemke: inaccurate solution, error %g", residual)
+			}
+			return z, nil
+		}
+	}
+
+	return nil, fmt.Errorf("lemke: no solution after %d pivots", LEMKE_MAX_PIVOTS_FACTOR*n)
+}
+
+// complementarityError is the largest violation of w >= 0 and w * z = 0 by the solution
+func complementarityError(m [][]float64, q, z []float64) float64 {
+	worst := 0.0
+	for i := range q {
+		w := q[i]
+		for j := range z {
+			w += m[i][j] * z[j]
+		}
+		worst = math.Max(worst, math.Max(-w, math.Abs(w*z[i])))
+	}
+	return worst
+}
+
+// complement of w_i is z_i and the other way round
+func complement(variable, n int) int {
+	if variable < n {
+		return variable + n
+	}
+	return variable - n
+}
+
+// ratioTest picks the row that leaves the basis when the column enters it. On ties z0 leaves
+// first, because that ends the algorithm, then the lowest row to avoid cycling
+func ratioTest(tableau [][]float64, basis []int, column, artificial int) int {
+	rhs := len(tableau[0]) - 1
+
+	best := -1
+	bestRatio := math.Inf(1)
+	for i, row := range tableau {
+		if row[column] <= LEMKE_EPSILON {
+			continue
+		}
+
+		ratio := row[rhs] / row[column]
+		if ratio < bestRatio-LEMKE_EPSILON ||
+			(ratio <= bestRatio+LEMKE_EPSILON && basis[i] == artificial && basis[best] != artificial) {
+			best = i
+			bestRatio = ratio
+		}
+	}
+
+	return best
+}
+
+// pivot makes the column basic in the row and returns the variable that left the basis
+func pivot(tableau [][]float64, basis []int, row, column int) int {
+	pivotRow := tableau[row]
+	scale := 1 / pivotRow[column]
+	for j := range pivotRow {
+		pivotRow[j] *= scale
+	}
+
+	for i, other := range tableau {
+		if i == row || other[column] == 0 {
+			continue
+		}
+
+		factor := other[column]
+		for j := range other {
+			other[j] -= factor * pivotRow[j]
+		}
+	}
+
+	leaving := basis[row]
+	basis[row] = column
+	return leaving
+}
@@ -0,0 +1,80 @@
+package lcp
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func TestLemke(t *testing.T) {
+	pd2 := [][]float64{{2, 1}, {1, 2}}
+	pd3 := [][]float64{{4, 1, 0}, {1, 3, 1}, {0, 1, 2}}
+
+	cases := []struct {
+		name string
+		m    [][]float64
+		q    []float64
+		want []float64
+	}{
+		{"empty", nil, nil, []float64{}},
+		{"non negative q is the trivial answer", pd2, []float64{0, 3}, []float64{0, 0}},
+		// every w is 0, z solves M z = -q
+		{"2x2 all active", pd2, []float64{-5, -6}, []float64{4.0 / 3, 7.0 / 3}},
+		{"2x2 one active", pd2, []float64{-1, 3}, []float64{0.5, 0}},
+		{"3x3 all active", pd3, []float64{-1, -2, -3}, []float64{2.0 / 9, 1.0 / 9, 13.0 / 9}},
+		// w_1 = 4 + 1/4 + 3/2 stays positive
+		{"3x3 middle inactive", pd3, []float64{-1, 4, -3}, []float64{0.25, 0, 1.5}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			z, err := lemke(tc.m, tc.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(z) != len(tc.want) {
+				t.Fatalf("z = %v, want %v", z, tc.want)
+			}
+			for i := range z {
+				if math.Abs(z[i]-tc.want[i]) > tolerance {
+					t.Errorf("z = %v, want %v", z, tc.want)
+					break
+				}
+			}
+			if residual := complementarityError(tc.m, tc.q, z); residual > tolerance {
+				t.Errorf("complementarity error %v", residual)
+			}
+		})
+	}
+}
+
+// w = -z - 1 is negative for every z >= 0, the problem has no solution
+func TestLemkeRayTermination(t *testing.T) {
+	_, err := lemke([][]float64{{-1}}, []float64{-1})
+	if err == nil || !strings.Contains(err.Error(), "ray termination") {
+		t.Errorf("err = %v, want a ray termination", err)
+	}
+}
+
+func TestComplementarityError(t *testing.T) {
+	identity := [][]float64{{1, 0}, {0, 1}}
+	cases := []struct {
+		name string
+		q, z []float64
+		want float64
+	}{
+		{"solution", []float64{-1, 2}, []float64{1, 0}, 0},
+		// w_0 = -1
+		{"negative w", []float64{-1, 2}, []float64{0, 0}, 1},
+		// w_1 = 2.5 next to z_1 = 0.5
+		{"both positive", []float64{-1, 2}, []float64{1, 0.5}, 1.25},
+	}
+
+	for _, tc := range cases {
+		if got := complementarityError(identity, tc.q, tc.z); math.Abs(got-tc.want) > tolerance {
+			t.Errorf("%s: error %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package lcp
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"math"
+)
+
+// copies of the contacts the chosen resolver gets, the oracle solves them exactly
+var observed constraint.Buffer
+
+// Velocity is what a body should have after the step
+type Velocity struct {
+	Linear vector.Vector3D
+	// radians per second
+	Angular vector.Vector3D
+}
+
+// ObserveContact shows the contact to the oracle, the resolver gets it as usual
+func ObserveContact(c *contact.Contact) {
+	observed.Add(c)
+}
+
+// ObservePair is ObserveContact for a pair without a narrow phase, only spheres are supported
+func ObservePair(aID, bID int, objectPool *[]objects.Object) {
+	observed.AddPair(aID, bID, objectPool)
+}
+
+// Exact solves the observed contacts without touching the objects and returns
+// the velocities of the dynamic bodies by object id. It has to run before the resolver
+func Exact(objectPool *[]objects.Object) map[string]Velocity {
+	bodies, contacts := buildSystem(observed.Take(), objectPool)
+	solveIslands(bodies, contacts)
+
+	exact := make(map[string]Velocity, len(bodies))
+	for _, body := range bodies {
+		if body.InvMass == 0 {
+			continue
+		}
+		exact[body.Object.GetId()] = Velocity{Linear: body.Velocity, Angular: body.Omega}
+	}
+
+	return exact
+}
+
+// Drift returns the largest differences between the exact velocities and the ones the resolver left
+func Drift(exact map[string]Velocity, objectPool *[]objects.Object) (float64, float64) {
+	linear, angular := 0.0, 0.0
+
+	for _, obj := range *objectPool {
+		velocity, ok := exact[obj.GetId()]
+		if !ok {
+			continue
+		}
+
+		current, errVel := obj.GetVelocity()
+		rotation, errRot := obj.GetRotation()
+		if errVel != nil || errRot != nil {
+			log.Printf("LCP oracle: failed to get the velocity of %s: %v, %v", obj.GetId(), errVel, errRot)
+			continue
+		}
+
+		linear = math.Max(linear, current.Sub(velocity.Linear).Length())
+		angular = math.Max(angular, rotation.Radians().Sub(velocity.Angular).Length())
+	}
+
+	return linear, angular
+}
+
+// LogDrift reports how far the resolver went from the exact solution
+func LogDrift(exact map[string]Velocity, objectPool *[]objects.Object, resolveAlgorithm string) {
+	if len(exact) == 0 {
+		return
+	}
+
+	linear, angular := Drift(exact, objectPool)
+	log.Printf("LCP oracle: %s, %d bodies, max velocity drift %.6f, max angular drift %.6f rad", resolveAlgorithm, len(exact), linear, angular)
+}
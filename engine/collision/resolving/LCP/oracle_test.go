@@ -0,0 +1,100 @@
+package lcp
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"testing"
+)
+
+// chain is a row of four touching spheres, the outer ones run into it from both sides.
+// A Gauss-Seidel sweep over it in order undoes its first contact with the last one
+func chain() ([]objects.Object, [][2]int) {
+	pool := make([]objects.Object, 4)
+	for i := range pool {
+		pool[i] = objectstest.Sphere(string(rune('a'+i)), vector.Vector3D{X: 1.98 * float64(i)}, 1)
+	}
+	pool[0].ApplyVelocity(vector.Vector3D{X: 2})
+	pool[3].ApplyVelocity(vector.Vector3D{X: -2})
+
+	return pool, [][2]int{{0, 1}, {1, 2}, {2, 3}}
+}
+
+func momentum(pool []objects.Object) vector.Vector3D {
+	total := vector.Vector3D{}
+	for _, obj := range pool {
+		velocity, _ := obj.GetVelocity()
+		total = *total.Add(*velocity.Mul(obj.GetMass()))
+	}
+	return total
+}
+
+func TestOracle(t *testing.T) {
+	solvers := []struct {
+		name  string
+		solve func(pool []objects.Object, pairs [][2]int)
+		// the largest drift from the exact velocities the solver may have, and the smallest one
+		atMost, atLeast float64
+	}{
+		{
+			name: "LCP",
+			solve: func(pool []objects.Object, pairs [][2]int) {
+				for _, pair := range pairs {
+					AddPair(pair[0], pair[1], &pool)
+				}
+				LCPNoParallel(&pool)
+			},
+			atMost: 1e-6,
+		},
+		{
+			// one Gauss-Seidel sweep is far from converged on the chain
+			name: "PGS, one iteration",
+			solve: func(pool []objects.Object, pairs [][2]int) {
+				gathered := make([]contact.Contact, 0, len(pairs))
+				for _, pair := range pairs {
+					c, _ := contact.SphereContact(pair[0], pair[1], &pool)
+					gathered = append(gathered, *c)
+				}
+				bodies, contacts := buildSystem(gathered, &pool)
+				for c := range contacts {
+					contacts[c].SolveNormal(contacts[c].Target, bodies)
+					contacts[c].SolveFriction(bodies)
+				}
+				constraint.WriteBack(bodies)
+			},
+			atMost:  10,
+			atLeast: 0.1,
+		},
+	}
+
+	for _, solver := range solvers {
+		t.Run(solver.name, func(t *testing.T) {
+			pool, pairs := chain()
+			before := momentum(pool)
+
+			for _, pair := range pairs {
+				ObservePair(pair[0], pair[1], &pool)
+			}
+			exact := Exact(&pool)
+			if len(exact) != len(pool) {
+				t.Fatalf("exact velocities of %d bodies, want %d", len(exact), len(pool))
+			}
+			// the oracle leaves the bodies to the resolver
+			if velocity, _ := pool[0].GetVelocity(); *velocity != (vector.Vector3D{X: 2}) {
+				t.Fatalf("Exact changed the velocity to %v", *velocity)
+			}
+
+			solver.solve(pool, pairs)
+
+			linear, angular := Drift(exact, &pool)
+			if linear > solver.atMost || angular > solver.atMost || linear < solver.atLeast {
+				t.Errorf("drift %v m/s, %v rad/s, want %v..%v", linear, angular, solver.atLeast, solver.atMost)
+			}
+			if change := momentum(pool).Sub(before).Length(); change > 1e-9 {
+				t.Errorf("momentum changed by %v", change)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package msi
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+)
+
+const (
+	// warm starting does most of the work, so a few iterations are enough
+	MSI_ITERATIONS = 4
+	// part of the previous step impulse the solver starts with, the cached impulse also
+	// holds the Baumgarte push, starting from all of it makes resting stacks hop
+	WARM_START_FACTOR = 0.8
+	// if the normal turned more than that (cosine) the cached impulse belongs to another contact
+	NORMAL_TOLERANCE = 0.9
+)
+
+// cacheKey finds the same contact in the next frame, the ids are sorted
+// so it doesn't matter which object the narrow phase called A
+type cacheKey struct {
+	idA     string
+	idB     string
+	feature int
+}
+
+// cachedImpulse is stored in the order of the key: the normal points from key A to key B,
+// the friction impulse is a world vector, because the tangents are rebuilt every frame
+type cachedImpulse struct {
+	normal   vector.Vector3D
+	impulse  float64
+	friction vector.Vector3D
+}
+
+var (
+	// contacts of the current step
+	gathered constraint.Buffer
+	// accumulated impulses of the previous step
+	cache = make(map[cacheKey]cachedImpulse)
+)
+
+// AddContact stores the contact until the end of the step, the system is solved by MSINoParallel
+func AddContact(c *contact.Contact) {
+	gathered.Add(c)
+}
+
+// AddPair is AddContact for a pair without a narrow phase, only spheres are supported
+func AddPair(aID, bID int, objectPool *[]objects.Object) {
+	gathered.AddPair(aID, bID, objectPool)
+}
+
+// MSINoParallel applies sequential impulses to all contacts of the step, starting from
+// the impulses the same contacts had in the previous step
+func MSINoParallel(objectPool *[]objects.Object) {
+	bodies, contacts := constraint.Build(gathered.Take(), objectPool)
+
+	constraint.SetTargets(contacts, constraint.BAUMGARTE_BIAS)
+	for i := range contacts {
+		warmStart(&contacts[i], bodies)
+	}
+
+	for i := 0; i < MSI_ITERATIONS; i++ {
+		for c := range contacts {
+			contacts[c].SolveNormal(contacts[c].Target, bodies)
+			contacts[c].SolveFriction(bodies)
+		}
+	}
+
+	// contacts that are gone this step are forgotten
+	cache = make(map[cacheKey]cachedImpulse, len(contacts))
+	for c := range contacts {
+		store(&contacts[c], bodies)
+	}
+
+	constraint.WriteBack(bodies)
+}
+
+// keyOf returns the cache key of the contact and whether A and B are swapped in it
+func keyOf(c *constraint.Contact, bodies []constraint.Body) (cacheKey, bool) {
+	idA := bodies[c.A].Object.GetId()
+	idB := bodies[c.B].Object.GetId()
+	if idA > idB {
+		return cacheKey{idA: idB, idB: idA, feature: c.Feature}, true
+	}
+
+	return cacheKey{idA: idA, idB: idB, feature: c.Feature}, false
+}
+
+// warmStart applies the cached impulses of the contact to the bodies
+func warmStart(c *constraint.Contact, bodies []constraint.Body) {
+	key, swapped := keyOf(c, bodies)
+	cached, ok := cache[key]
+	if !ok {
+		return
+	}
+
+	normal := cached.normal
+	friction := cached.friction
+	if swapped {
+		normal = *normal.Negate()
+		friction = *friction.Negate()
+	}
+	if normal.Dot(c.Normal) < NORMAL_TOLERANCE {
+		return
+	}
+
+	c.NormalImpulse = WARM_START_FACTOR * cached.impulse
+	for t := range c.Tangents {
+		c.TangentImpulse[t] = WARM_START_FACTOR * friction.Dot(c.Tangents[t])
+	}
+
+	impulse := c.Normal.Mul(c.NormalImpulse).
+		Add(*c.Tangents[0].Mul(c.TangentImpulse[0])).
+		Add(*c.Tangents[1].Mul(c.TangentImpulse[1]))
+	c.ApplyImpulse(*impulse, bodies)
+}
+
+func store(c *constraint.Contact, bodies []constraint.Body) {
+	key, swapped := keyOf(c, bodies)
+
+	normal := c.Normal
+	friction := *c.Tangents[0].Mul(c.TangentImpulse[0]).Add(*c.Tangents[1].Mul(c.TangentImpulse[1]))
+	if swapped {
+		normal = *normal.Negate()
+		friction = *friction.Negate()
+	}
+
+	cache[key] = cachedImpulse{
+		normal:   normal,
+		impulse:  c.NormalImpulse,
+		friction: friction,
+	}
+}
@@ -0,0 +1,100 @@
+package msi
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+// the impulse of a resting contact is kept for the next frame and dropped once the contact is gone
+func TestWarmStartCache(t *testing.T) {
+	cases := []struct {
+		name string
+		// where B is in the second frame and whether the pool lists B first
+		posB    vector.Vector3D
+		swapped bool
+		// the second frame starts from the cached impulse
+		warm bool
+		// the contact is still cached after the second frame
+		cached bool
+	}{
+		{
+			name:   "resting",
+			posB:   vector.Vector3D{X: 1.9},
+			warm:   true,
+			cached: true,
+		},
+		{
+			// the cache doesn't depend on the order of the pool
+			name:    "swapped",
+			posB:    vector.Vector3D{X: 1.9},
+			swapped: true,
+			warm:    true,
+			cached:  true,
+		},
+		{
+			// the normal turned, the cached impulse belongs to another contact
+			name:   "turned",
+			posB:   vector.Vector3D{Y: 1.9},
+			cached: true,
+		},
+		{
+			name: "apart",
+			posB: vector.Vector3D{X: 2.5},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache = make(map[cacheKey]cachedImpulse)
+			a := objectstest.Sphere("a", vector.Vector3D{}, 1)
+			b := objectstest.Sphere("b", vector.Vector3D{X: 1.9}, 1)
+			pool := []objects.Object{a, b}
+
+			AddPair(0, 1, &pool)
+			MSINoParallel(&pool)
+
+			if len(cache) != 1 {
+				t.Fatalf("%d contacts cached after the first frame, want 1", len(cache))
+			}
+			first := cache[cacheKey{idA: "a", idB: "b"}]
+			if first.impulse <= 0 {
+				t.Fatalf("cached impulse = %v, the overlap was not pushed apart", first.impulse)
+			}
+
+			a.ApplyVelocity(vector.Vector3D{})
+			b.ApplyVelocity(vector.Vector3D{})
+			b.SetPosition(tc.posB)
+			b.Update(0)
+			if tc.swapped {
+				pool = []objects.Object{b, a}
+			}
+
+			if c, ok := contact.SphereContact(0, 1, &pool); ok {
+				bodies, contacts := constraint.Build([]contact.Contact{*c}, &pool)
+				warmStart(&contacts[0], bodies)
+
+				want := 0.0
+				if tc.warm {
+					want = WARM_START_FACTOR * first.impulse
+				}
+				if math.Abs(contacts[0].NormalImpulse-want) > 1e-9 {
+					t.Errorf("warm started with %v, want %v", contacts[0].NormalImpulse, want)
+				}
+			} else if tc.warm {
+				t.Fatal("no contact in the second frame")
+			}
+
+			AddPair(0, 1, &pool)
+			MSINoParallel(&pool)
+
+			if _, ok := cache[cacheKey{idA: "a", idB: "b"}]; ok != tc.cached {
+				t.Errorf("cached = %v after the second frame, want %v", ok, tc.cached)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package pgs
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+)
+
+const PGS_ITERATIONS = 10
+
+// contacts of the current step
+var gathered constraint.Buffer
+
+// AddContact stores the contact until the end of the step, the system is solved by PGSNoParallel
+func AddContact(c *contact.Contact) {
+	gathered.Add(c)
+}
+
+// AddPair is AddContact for a pair without a narrow phase, only spheres are supported
+func AddPair(aID, bID int, objectPool *[]objects.Object) {
+	gathered.AddPair(aID, bID, objectPool)
+}
+
+// PGSNoParallel solves all contacts gathered during the step as one system
+func PGSNoParallel(objectPool *[]objects.Object) {
+	bodies, contacts := buildSystem(objectPool)
+	if len(contacts) == 0 {
+		return
+	}
+
+	for i := 0; i < PGS_ITERATIONS; i++ {
+		for c := range contacts {
+			solveContact(&contacts[c], bodies)
+		}
+	}
+
+	constraint.WriteBack(bodies)
+}
+
+func buildSystem(objectPool *[]objects.Object) ([]constraint.Body, []constraint.Contact) {
+	bodies, contacts := constraint.Build(gathered.Take(), objectPool)
+	constraint.SetTargets(contacts, constraint.BAUMGARTE_BIAS)
+
+	return bodies, contacts
+}
+
+// solveContact is one Gauss-Seidel step for a contact, the accumulated impulses are projected
+// on their bounds: the normal one can only push, friction stays inside the Coulomb cone
+func solveContact(c *constraint.Contact, bodies []constraint.Body) {
+	c.SolveNormal(c.Target, bodies)
+	c.SolveFriction(bodies)
+}
@@ -0,0 +1,94 @@
+package pgs
+
+import (
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/objects"
+	"runtime"
+	"sync"
+)
+
+// smaller batches are solved on the calling goroutine, starting workers would cost more
+const MIN_PARALLEL_BATCH = 32
+
+// PGSParallelNonTrivial solves the gathered contacts colour by colour. Contacts of one colour
+// share no dynamic body, so a batch is solved by several goroutines without locks, and the
+// batches follow each other like the contacts do in the sequential solver
+func PGSParallelNonTrivial(objectPool *[]objects.Object) {
+	bodies, contacts := buildSystem(objectPool)
+	if len(contacts) == 0 {
+		return
+	}
+
+	batches := colourContacts(contacts, bodies)
+
+	workersCount := runtime.NumCPU()
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < PGS_ITERATIONS; i++ {
+		for _, batch := range batches {
+			if len(batch) < MIN_PARALLEL_BATCH || workersCount < 2 {
+				for _, c := range batch {
+					solveContact(&contacts[c], bodies)
+				}
+				continue
+			}
+
+			chunkSize := (len(batch) + workersCount - 1) / workersCount
+			for start := 0; start < len(batch); start += chunkSize {
+				end := min(start+chunkSize, len(batch))
+
+				wg.Add(1)
+				go func(chunk []int) {
+					defer wg.Done()
+					for _, c := range chunk {
+						solveContact(&contacts[c], bodies)
+					}
+				}(batch[start:end])
+			}
+			wg.Wait()
+		}
+	}
+
+	constraint.WriteBack(bodies)
+}
+
+// colourContacts greedily splits the contacts into batches where no dynamic body appears
+// twice. Static bodies are never written by the solver, so any number of contacts may share them
+func colourContacts(contacts []constraint.Contact, bodies []constraint.Body) [][]int {
+	batches := make([][]int, 0)
+
+	remaining := make([]int, len(contacts))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	// the last colour a body was taken by
+	taken := make([]int, len(bodies))
+
+	for colour := 1; len(remaining) > 0; colour++ {
+		batch := make([]int, 0, len(remaining))
+		// the skipped contacts are written over the ones already read
+		next := remaining[:0]
+
+		for _, c := range remaining {
+			a, b := contacts[c].A, contacts[c].B
+			if taken[a] == colour || taken[b] == colour {
+				next = append(next, c)
+				continue
+			}
+
+			batch = append(batch, c)
+			if bodies[a].InvMass > 0 {
+				taken[a] = colour
+			}
+			if bodies[b].InvMass > 0 {
+				taken[b] = colour
+			}
+		}
+
+		batches = append(batches, batch)
+		remaining = next
+	}
+
+	return batches
+}
@@ -0,0 +1,135 @@
+package pgs
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// the colour batches solve the contacts of a cluster in another order than the sequential solver,
+// both converge towards the same impulses but stop after PGS_ITERATIONS at slightly different ones
+const PARALLEL_TOLERANCE = 0.05
+
+// scene is a fixed crowded scene with its touching pairs, every solver starts it from the same state
+type scene struct {
+	pool       []objects.Object
+	pairs      [][2]int
+	velocities []vector.Vector3D
+	rotations  []vector.Angle3D
+}
+
+func newScene(n int, seed int64) *scene {
+	s := &scene{pool: objectstest.RandomSpheres(n, seed)}
+	s.pairs = objectstest.TouchingPairs(s.pool)
+	for _, obj := range s.pool {
+		velocity, _ := obj.GetVelocity()
+		rotation, _ := obj.GetRotation()
+		s.velocities = append(s.velocities, *velocity)
+		s.rotations = append(s.rotations, *rotation)
+	}
+	return s
+}
+
+// solve restores the scene, gathers its pairs, runs the solver and returns the velocities it left
+func (s *scene) solve(solver func(*[]objects.Object)) ([]vector.Vector3D, []vector.Vector3D) {
+	for i, obj := range s.pool {
+		obj.ApplyVelocity(s.velocities[i])
+		rotation, _ := obj.GetRotation()
+		obj.ApplyRotation(vector.Angle3D{X: s.rotations[i].X - rotation.X, Y: s.rotations[i].Y - rotation.Y, Z: s.rotations[i].Z - rotation.Z})
+	}
+	for _, pair := range s.pairs {
+		AddPair(pair[0], pair[1], &s.pool)
+	}
+
+	solver(&s.pool)
+
+	velocities := make([]vector.Vector3D, len(s.pool))
+	omegas := make([]vector.Vector3D, len(s.pool))
+	for i, obj := range s.pool {
+		velocity, _ := obj.GetVelocity()
+		rotation, _ := obj.GetRotation()
+		velocities[i] = *velocity
+		omegas[i] = *rotation.Radians()
+	}
+	return velocities, omegas
+}
+
+func TestParallelMatchesSequential(t *testing.T) {
+	s := newScene(2048, 1)
+	if len(s.pairs) == 0 {
+		t.Fatal("the scene has no contacts")
+	}
+
+	sequential, sequentialOmegas := s.solve(PGSNoParallel)
+	parallel, parallelOmegas := s.solve(PGSParallelNonTrivial)
+
+	worst, worstOmega := 0.0, 0.0
+	for i := range s.pool {
+		worst = math.Max(worst, parallel[i].Sub(sequential[i]).Length())
+		worstOmega = math.Max(worstOmega, parallelOmegas[i].Sub(sequentialOmegas[i]).Length())
+	}
+	t.Logf("%d contacts, largest difference %.3g m/s, %.3g rad/s", len(s.pairs), worst, worstOmega)
+
+	if worst > PARALLEL_TOLERANCE || worstOmega > PARALLEL_TOLERANCE {
+		t.Errorf("parallel solver differs from the sequential one by %v m/s and %v rad/s, tolerance %v",
+			worst, worstOmega, PARALLEL_TOLERANCE)
+	}
+}
+
+func TestColourContacts(t *testing.T) {
+	crowded := newScene(2048, 2)
+
+	// small spheres around a static one, the static body may be in every contact of a batch
+	hub := objectstest.Sphere("hub", vector.Vector3D{}, 5)
+	hub.SetMass(0)
+	star := []objects.Object{hub}
+	for i := 0; i < 12; i++ {
+		angle := float64(i) * math.Pi / 6
+		star = append(star, objectstest.Sphere(fmt.Sprint("satellite_", i), vector.Vector3D{X: 5.9 * math.Cos(angle), Y: 5.9 * math.Sin(angle)}, 1))
+	}
+
+	for name, pool := range map[string][]objects.Object{"crowded": crowded.pool, "star": star} {
+		t.Run(name, func(t *testing.T) {
+			pairs := objectstest.TouchingPairs(pool)
+			for _, pair := range pairs {
+				AddPair(pair[0], pair[1], &pool)
+			}
+			bodies, contacts := buildSystem(&pool)
+			if len(contacts) != len(pairs) {
+				t.Fatalf("%d contacts of %d pairs", len(contacts), len(pairs))
+			}
+
+			batches := colourContacts(contacts, bodies)
+
+			coloured := make([]int, len(contacts))
+			for colour, batch := range batches {
+				used := make(map[int]int)
+				for _, c := range batch {
+					coloured[c]++
+					for _, body := range []int{contacts[c].A, contacts[c].B} {
+						if bodies[body].InvMass == 0 {
+							continue
+						}
+						if other, ok := used[body]; ok {
+							t.Errorf("colour %d: contacts %d and %d share the dynamic body %s", colour, other, c, bodies[body].Object.GetId())
+						}
+						used[body] = c
+					}
+				}
+			}
+			for c, count := range coloured {
+				if count != 1 {
+					t.Errorf("contact %d is in %d batches", c, count)
+				}
+			}
+
+			// the satellites only touch the hub, a static body never splits a batch
+			if name == "star" && len(batches) != 1 {
+				t.Errorf("star: %d batches, want 1", len(batches))
+			}
+		})
+	}
+}
@@ -1,150 +1,146 @@
 package tgs
 
 import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/constants"
 	"BachelorThesis/engine/objects"
 	"BachelorThesis/engine/vector"
 	"log"
-	"math"
 )
 
 const (
-	TGS_ITERATIONS = 10
-	RESTITUTION    = 0.5
-	SLOP           = 0.001
-	BAUMGARTE_BIAS = 0.0
+	TGS_SUBSTEPS   = 8
+	TGS_ITERATIONS = 1 // итераций скоростей на каждом подшаге
+	// Доля проникновения, выталкиваемая за подшаг. Меньше constraint.BAUMGARTE_BIAS:
+	// зазор пересчитывается на каждом подшаге, и за шаг проникновение выталкивается TGS_SUBSTEPS раз
+	TGS_BAUMGARTE_BIAS = 0.1
 )
 
-func TGSNoParallel(aID, bID int, objectPool *[]objects.Object) {
-	if aID == bID {
-		log.Panicf("Object %d (ID: %s) is the same as object %d (ID: %s)", aID, (*objectPool)[aID].GetId(), bID, (*objectPool)[bID].GetId())
-	}
+// контакты текущего шага
+var gathered constraint.Buffer
 
-	objA := (*objectPool)[aID]
-	objB := (*objectPool)[bID]
+// AddContact сохраняет контакт до конца шага, систему решает TGSNoParallel
+func AddContact(c *contact.Contact) {
+	gathered.Add(c)
+}
 
-	sphereA, okA := objA.(*objects.Sphere)
-	if !okA {
-		log.Printf("TGS: Объект %d (ID: %s) не является сферой, пропуск разрешения коллизии.", aID, objA.GetId())
-		return
-	}
-	sphereB, okB := objB.(*objects.Sphere)
-	if !okB {
-		log.Printf("TGS: Объект %d (ID: %s) не является сферой, пропуск разрешения коллизии.", bID, objB.GetId())
-		return
-	}
+// AddPair - AddContact для пары без узкой фазы, поддерживаются только сферы
+func AddPair(aID, bID int, objectPool *[]objects.Object) {
+	gathered.AddPair(aID, bID, objectPool)
+}
 
-	// Получаем свойства объектов
-	posA, errA := sphereA.GetPosition()
-	velA, errVelA := sphereA.GetVelocity()
-	radiusA := sphereA.GetRadius()
-	// Предполагается, что у Sphere есть метод GetMass() float64.
-	// Если нет, или масса = 0, то по умолчанию масса 1.0 (для движущихся объектов)
-	// или 0.0 (для статических/бесконечно массивных объектов).
-	massA := 1.0 // Значение по умолчанию, заменить на sphereA.GetMass(), если доступно
-
-	if errA != nil || errVelA != nil {
-		log.Printf("TGS: Ошибка получения свойств для объекта А (%s): %v, %v", sphereA.GetId(), errA, errVelA)
+// TGSNoParallel решает все контакты шага, разбивая шаг на TGS_SUBSTEPS подшагов.
+// Интегратор уже сдвинул тела на весь шаг, поэтому шаг проигрывается заново с положений,
+// сохранённых до интегрирования (GetStartPose): между подшагами интегрируются смещения тел,
+// и по ним пересчитывается зазор каждого контакта
+func TGSNoParallel(objectPool *[]objects.Object) {
+	bodies, contacts := constraint.Build(gathered.Take(), objectPool)
+	if len(contacts) == 0 {
 		return
 	}
 
-	posB, errB := sphereB.GetPosition()
-	velB, errVelB := sphereB.GetVelocity()
-	radiusB := sphereB.GetRadius()
-	massB := 1.0 // Значение по умолчанию, заменить на sphereB.GetMass(), если доступно
+	h := constants.TimeStep / TGS_SUBSTEPS
 
-	if errB != nil || errVelB != nil {
-		log.Printf("TGS: Ошибка получения свойств для объекта B (%s): %v, %v", sphereB.GetId(), errB, errVelB)
-		return
+	// Смещения и повороты тел относительно положения, в котором найдены контакты.
+	// В начале шага тело было в сохранённом положении. Из скорости его не восстановить:
+	// Verlet, RK4 и затухание двигают тело не на v*dt, а CCD откатывает его к моменту удара
+	offsets := make([]vector.Vector3D, len(bodies))
+	turns := make([]vector.Vector3D, len(bodies))
+	for i := range bodies {
+		offsets[i], turns[i] = startOffset(bodies[i].Object)
 	}
 
-	// Вычисляем вектор от B к A (направление нормали, отталкивающей B от A)
-	deltaPos := posA.Sub(*posB)
+	for s := 0; s < TGS_SUBSTEPS; s++ {
+		for i := 0; i < TGS_ITERATIONS; i++ {
+			for c := range contacts {
+				solveContact(&contacts[c], bodies, separation(&contacts[c], offsets, turns), h, true)
+			}
+		}
 
-	distanceSq := deltaPos.LengthSq()
-	sumRadii := radiusA + radiusB
-	sumRadiiSq := sumRadii * sumRadii
+		// Интегрируем положения на подшаг
+		for i := range bodies {
+			if bodies[i].InvMass == 0 {
+				continue
+			}
+			offsets[i] = *offsets[i].Add(*bodies[i].Velocity.Mul(h))
+			turns[i] = *turns[i].Add(*bodies[i].Omega.Mul(h))
+		}
+	}
 
-	// Если объекты не проникают или только касаются, разрешение не требуется для этого шага.
-	if distanceSq > sumRadiiSq {
-		return
+	// Релаксация: убираем из скоростей смещение Баумгарте, положения оно уже поправило
+	for c := range contacts {
+		solveContact(&contacts[c], bodies, separation(&contacts[c], offsets, turns), h, false)
 	}
 
-	distance := math.Sqrt(distanceSq)
-	if distance < 1e-9 { // Если центры совпадают или очень близки, выбираем произвольную нормаль
-		deltaPos = &vector.Vector3D{X: 1, Y: 0, Z: 0}
-		distance = 1.0 // Чтобы избежать деления на ноль
+	// Отскок считается один раз по скорости сближения до решения
+	for c := range contacts {
+		con := &contacts[c]
+		if con.Approach > -constraint.RESTITUTION_THRESHOLD || con.NormalImpulse == 0 {
+			continue
+		}
+		con.SolveNormal(-con.Material.Restitution*con.Approach, bodies)
 	}
 
-	normal := deltaPos.Normalize() // Нормализованный вектор от B к A
+	constraint.WriteBack(bodies)
+	writePositions(bodies, offsets, turns)
+}
 
-	// Вычисляем обратные массы для определения эффективной массы
-	// Если масса равна 0, это означает бесконечную массу (статический объект), поэтому обратная масса равна 0.
-	invMassA := 0.0
-	if massA > 1e-9 { // Проверка на ненулевую массу
-		invMassA = 1.0 / massA
-	}
-	invMassB := 0.0
-	if massB > 1e-9 { // Проверка на ненулевую массу
-		invMassB = 1.0 / massB
+// startOffset - смещение и поворот (вектор поворота, радианы) от текущего положения тела к положению в начале шага
+func startOffset(object objects.Object) (vector.Vector3D, vector.Vector3D) {
+	position, err := object.GetPosition()
+	if err != nil {
+		log.Printf("TGS: Ошибка получения положения объекта %s: %v", object.GetId(), err)
+		return vector.Vector3D{}, vector.Vector3D{}
 	}
 
-	// Если оба объекта статичны, импульс применить нельзя
-	if invMassA == 0 && invMassB == 0 {
-		return
-	}
+	startPosition, startOrientation := object.GetStartPose()
+	turn := startOrientation.Mul(*object.GetQuaternion().Conjugate()).ToRotationVector()
+	return *startPosition.Sub(*position), *turn
+}
 
-	// Сумма обратных масс, используемая в знаменателе формулы импульса
-	effectiveMassInverse := invMassA + invMassB
+// separation - текущий зазор контакта (отрицательный при проникновении) с учётом смещений тел
+// после обнаружения контакта. Поворот учитывается в линейном приближении: w x r
+func separation(c *constraint.Contact, offsets, turns []vector.Vector3D) float64 {
+	displacementA := offsets[c.A].Add(*turns[c.A].Cross(c.RA))
+	displacementB := offsets[c.B].Add(*turns[c.B].Cross(c.RB))
 
-	// Глубина проникновения
-	penetration := sumRadii - distance
+	return -c.Depth + displacementB.Sub(*displacementA).Dot(c.Normal)
+}
 
-	// Итерации TGS
-	for i := 0; i < TGS_ITERATIONS; i++ {
-		// Вычисляем текущую относительную скорость вдоль нормали
-		relativeVelocity := velA.Sub(*velB).Dot(*normal)
+func solveContact(c *constraint.Contact, bodies []constraint.Body, separation, h float64, useBias bool) {
+	// Целевая нормальная скорость:
+	// если тела ещё не касаются, им можно сближаться, пока зазор не закроется за подшаг;
+	// при проникновении тела расталкиваются со скоростью Баумгарте (кроме релаксации)
+	target := 0.0
+	if separation > 0 {
+		target = -separation / h
+	} else if useBias {
+		target = constraint.BaumgarteVelocity(-separation, TGS_BAUMGARTE_BIAS, h)
+	}
 
-		// Вычисляем смещение для позиционной коррекции (стабилизация Баумгарте)
-		// Это помогает предотвратить "проваливание" объектов, если они глубоко проникли
-		bias := 0.0
-		if penetration > SLOP {
-			bias = (BAUMGARTE_BIAS / float64(TGS_ITERATIONS)) * (penetration - SLOP)
-		}
+	c.SolveNormal(target, bodies)
+	c.SolveFriction(bodies)
+}
 
-		// Желаемая относительная скорость после столкновения (учитывая восстановление и смещение)
-		// Если объекты уже расходятся, не применяем дальнейший импульс для проникновения
-		if relativeVelocity >= 0 && bias == 0 {
-			// Объекты уже разделяются или находятся в покое и нет проникновения. Импульс не нужен.
+// writePositions переносит проигранный шаг на тела: положение, в которое их привёл Update,
+// заменяется на проинтегрированное по подшагам
+func writePositions(bodies []constraint.Body, offsets, turns []vector.Vector3D) {
+	for i, body := range bodies {
+		if body.InvMass == 0 {
 			continue
 		}
 
-		// Вычисляем величину импульса (lambda_change), необходимого для разрешения
-		// J = -( (1 + e) * v_rel_normal + bias ) / (1/m_A + 1/m_B)
-		impulseMagnitude := -((1+RESTITUTION)*relativeVelocity + bias) / effectiveMassInverse
-
-		// Применяем импульсы к текущим скоростям
-		// Это "последовательная" часть алгоритма: обновленные скорости используются немедленно.
-
-		// Импульс, применяемый к объекту A (вдоль нормали)
-		impulseVecA := normal.Mul(impulseMagnitude * invMassA)
-		newVelA := velA.Add(*impulseVecA)
-
-		// Импульс, применяемый к объекту B (в противоположном направлении от нормали)
-		impulseVecB := normal.Mul(impulseMagnitude * invMassB)
-		newVelB := velB.Sub(*impulseVecB)
-
-		// Обновляем скорости объектов в пуле
-		err := sphereA.ApplyVelocity(*newVelA)
+		position, err := body.Object.GetPosition()
 		if err != nil {
-			log.Printf("TGS: Ошибка применения скорости к объекту А (%s): %v", sphereA.GetId(), err)
-		}
-		err = sphereB.ApplyVelocity(*newVelB)
-		if err != nil {
-			log.Printf("TGS: Ошибка применения скорости к объекту B (%s): %v", sphereB.GetId(), err)
+			log.Printf("TGS: Ошибка получения положения объекта %s: %v", body.Object.GetId(), err)
+			continue
 		}
+		body.Object.SetPosition(*position.Add(offsets[i]))
 
-		// Обновляем локальные копии скоростей для следующей итерации внутри цикла
-		velA = newVelA
-		velB = newVelB
+		if turns[i] != (vector.Vector3D{}) {
+			orientation := body.Object.GetQuaternion()
+			body.Object.SetOrientation(*vector.RotationVectorQuaternion(turns[i]).Mul(orientation))
+		}
 	}
 }
@@ -0,0 +1,215 @@
+package tgs
+
+import (
+	"BachelorThesis/engine/collision/resolving/constraint"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-6
+
+var (
+	// без трения, чтобы проверять только нормальный отклик
+	inelastic = objects.Material{Name: "Inelastic", Density: 1}
+	elastic   = objects.Material{Name: "Elastic", Restitution: 1, Density: 1}
+)
+
+func TestTGS(t *testing.T) {
+	cases := []struct {
+		name     string
+		material objects.Material
+		// положения после Update, шаг начинается с pos - v*dt
+		posB       vector.Vector3D
+		velA, velB vector.Vector3D
+		staticB    bool
+		// относительная нормальная скорость B - A вдоль X после решения
+		wantRelative float64
+		// тела не решаются, скорости и положения не меняются
+		untouched bool
+	}{
+		{
+			name:         "head-on inelastic",
+			material:     inelastic,
+			posB:         vector.Vector3D{X: 2},
+			velA:         vector.Vector3D{X: 1},
+			velB:         vector.Vector3D{X: -1},
+			wantRelative: 0,
+		},
+		{
+			name:         "head-on elastic",
+			material:     elastic,
+			posB:         vector.Vector3D{X: 2},
+			velA:         vector.Vector3D{X: 1},
+			velB:         vector.Vector3D{X: -1},
+			wantRelative: 2,
+		},
+		{
+			name:      "separating",
+			material:  inelastic,
+			posB:      vector.Vector3D{X: 1.99},
+			velA:      vector.Vector3D{X: -3},
+			velB:      vector.Vector3D{X: 3},
+			untouched: true,
+		},
+		{
+			name:         "static body",
+			material:     inelastic,
+			posB:         vector.Vector3D{X: 2},
+			velA:         vector.Vector3D{X: 1},
+			staticB:      true,
+			wantRelative: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := objectstest.Sphere("a", *tc.velA.Mul(-constants.TimeStep), 1)
+			b := objectstest.Sphere("b", *tc.posB.Sub(*tc.velB.Mul(constants.TimeStep)), 1)
+			a.SetMaterial(tc.material)
+			b.SetMaterial(tc.material)
+			if tc.staticB {
+				b.SetMass(0)
+			}
+			a.ApplyVelocity(tc.velA)
+			b.ApplyVelocity(tc.velB)
+			a.Update(constants.TimeStep)
+			b.Update(constants.TimeStep)
+			pool := []objects.Object{a, b}
+
+			AddPair(0, 1, &pool)
+			TGSNoParallel(&pool)
+
+			velA, _ := a.GetVelocity()
+			velB, _ := b.GetVelocity()
+			posA, _ := a.GetPosition()
+			posB, _ := b.GetPosition()
+
+			if tc.untouched {
+				if *velA != tc.velA || *velB != tc.velB {
+					t.Errorf("velocities changed: %v, %v", *velA, *velB)
+				}
+				if posA.Sub(vector.Vector3D{}).Length() > tolerance || posB.Sub(tc.posB).Length() > tolerance {
+					t.Errorf("positions changed: %v, %v", *posA, *posB)
+				}
+				return
+			}
+
+			if got := velB.X - velA.X; math.Abs(got-tc.wantRelative) > tolerance {
+				t.Errorf("relative normal velocity = %v, want %v", got, tc.wantRelative)
+			}
+			if tc.staticB {
+				if *velB != (vector.Vector3D{}) || *posB != tc.posB {
+					t.Errorf("static body moved: velocity %v, position %v", *velB, *posB)
+				}
+			} else if momentum := velA.Add(*velB).Sub(*tc.velA.Add(tc.velB)); momentum.Length() > tolerance {
+				t.Errorf("momentum changed by %v", *momentum)
+			}
+			// подшаги не дают телам пройти друг в друга дальше допуска
+			if depth := 2 - posB.Sub(*posA).Length(); depth > constraint.SLOP+tolerance {
+				t.Errorf("spheres overlap by %v after the step", depth)
+			}
+		})
+	}
+}
+
+// глубокое проникновение выталкивается за несколько шагов
+func TestTGSDeepOverlap(t *testing.T) {
+	a := objectstest.Sphere("a", vector.Vector3D{}, 1)
+	b := objectstest.Sphere("b", vector.Vector3D{Y: 1}, 1)
+	a.SetMaterial(inelastic)
+	b.SetMaterial(inelastic)
+	pool := []objects.Object{a, b}
+
+	previous := 1.0
+	for step := 0; step < 5; step++ {
+		AddPair(0, 1, &pool)
+		TGSNoParallel(&pool)
+
+		posA, _ := a.GetPosition()
+		posB, _ := b.GetPosition()
+		depth := 2 - posB.Sub(*posA).Length()
+		if depth >= previous {
+			t.Fatalf("step %d: overlap %v did not shrink from %v", step, depth, previous)
+		}
+		if posA.X != 0 || posB.X != 0 || posA.Z != 0 || posB.Z != 0 {
+			t.Fatalf("step %d: spheres left the normal: %v, %v", step, *posA, *posB)
+		}
+		previous = depth
+
+		// как в движке: скорость после решения переносится в следующий шаг
+		for _, obj := range pool {
+			obj.SaveStartPose()
+			obj.Update(constants.TimeStep)
+		}
+	}
+}
+
+// Шаг проигрывается с сохранённого положения, даже если тело сдвинуто не на v*dt:
+// здесь его будто откатил CCD и повернуло затухание
+func TestTGSStartPose(t *testing.T) {
+	a := objectstest.Sphere("a", vector.Vector3D{}, 1)
+	a.SetMaterial(inelastic)
+	a.ApplyVelocity(vector.Vector3D{X: 6})
+	a.SetPosition(vector.Vector3D{X: 0.5})
+	a.SetOrientation(*vector.AxisAngleQuaternion(vector.Vector3D{Z: 1}, 0.3))
+	a.Update(0)
+
+	// неподвижная стена впереди, за шаг A до неё не доходит
+	b := objectstest.Sphere("b", vector.Vector3D{X: 2.45}, 1)
+	b.SetMass(0)
+	pool := []objects.Object{a, b}
+
+	AddPair(0, 1, &pool)
+	TGSNoParallel(&pool)
+
+	position, _ := a.GetPosition()
+	if want := (vector.Vector3D{X: 6 * constants.TimeStep}); position.Sub(want).Length() > tolerance {
+		t.Errorf("position %v, want %v", *position, want)
+	}
+	if _, angle := a.GetQuaternion().ToAxisAngle(); angle > tolerance {
+		t.Errorf("the body is still turned by %v rad", angle)
+	}
+	if velocity, _ := a.GetVelocity(); *velocity != (vector.Vector3D{X: 6}) {
+		t.Errorf("velocity %v changed, the bodies never touched", *velocity)
+	}
+}
+
+// BenchmarkTGS решает контакты касающихся сфер, перед каждой итерацией сцена восстанавливается
+func BenchmarkTGS(b *testing.B) {
+	for _, n := range objectstest.Sizes {
+		b.Run(objectstest.SizeName(n), func(b *testing.B) {
+			pool := objectstest.RandomSpheres(n, 1)
+			pairs := objectstest.TouchingPairs(pool)
+
+			positions := make([]vector.Vector3D, n)
+			velocities := make([]vector.Vector3D, n)
+			for i, obj := range pool {
+				position, _ := obj.GetPosition()
+				velocity, _ := obj.GetVelocity()
+				positions[i], velocities[i] = *position, *velocity
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for j, obj := range pool {
+					obj.SetPosition(positions[j])
+					obj.ApplyVelocity(velocities[j])
+					obj.SetOrientation(*vector.IdentityQuaternion())
+					rotation, _ := obj.GetRotation()
+					obj.ApplyRotation(vector.Angle3D{X: -rotation.X, Y: -rotation.Y, Z: -rotation.Z})
+				}
+				for _, pair := range pairs {
+					AddPair(pair[0], pair[1], &pool)
+				}
+				b.StartTimer()
+
+				TGSNoParallel(&pool)
+			}
+		})
+	}
+}
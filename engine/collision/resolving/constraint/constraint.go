@@ -0,0 +1,298 @@
+package constraint
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"log"
+	"math"
+	"sync"
+)
+
+// stabilization shared by the solvers
+const (
+	// penetration (m) that is left, so resting contacts keep touching
+	SLOP = 0.001
+	// part of the penetration pushed out per step
+	BAUMGARTE_BIAS = 0.2
+	// slower approaches (m/s) don't bounce, otherwise bodies resting under gravity hop
+	RESTITUTION_THRESHOLD = 0.5
+)
+
+// Buffer keeps the contacts of the current step for a global solver,
+// narrow phases may add them from several goroutines
+type Buffer struct {
+	mu       sync.Mutex
+	contacts []contact.Contact
+}
+
+func (b *Buffer) Add(c *contact.Contact) {
+	if c.AID == c.BID {
+		log.Panicf("Object %d is the same as object %d", c.AID, c.BID)
+	}
+
+	b.mu.Lock()
+	b.contacts = append(b.contacts, *c)
+	b.mu.Unlock()
+}
+
+// AddPair is Add for a pair without a narrow phase, only spheres are supported
+func (b *Buffer) AddPair(aID, bID int, objectPool *[]objects.Object) {
+	c, ok := contact.SphereContact(aID, bID, objectPool)
+	if !ok {
+		return
+	}
+
+	b.Add(c)
+}
+
+// Take returns the gathered contacts and empties the buffer
+func (b *Buffer) Take() []contact.Contact {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	contacts := b.contacts
+	b.contacts = nil
+	return contacts
+}
+
+// Body is the velocity state of a body while the system is being solved
+type Body struct {
+	Object     objects.Object
+	InvMass    float64
+	InvInertia vector.Matrix3D
+
+	Velocity vector.Vector3D
+	// angular velocity in radians per second
+	Omega vector.Vector3D
+
+	// velocities the body had before solving
+	StartVelocity vector.Vector3D
+	StartOmega    vector.Vector3D
+}
+
+// Contact is one contact as the solvers see it: a normal row and two friction rows
+// with their accumulated impulses
+type Contact struct {
+	// indices in the bodies slice
+	A int
+	B int
+
+	Feature int
+
+	// from A to B
+	Normal   vector.Vector3D
+	Tangents [2]vector.Vector3D
+
+	// from the centres of mass to the contact point
+	RA vector.Vector3D
+	RB vector.Vector3D
+
+	NormalMass  float64
+	TangentMass [2]float64
+
+	Depth float64
+	// normal relative velocity before solving, negative when the bodies approach
+	Approach float64
+	Material objects.PairMaterial
+
+	// normal relative velocity the solver aims for, set by the solver
+	Target float64
+
+	NormalImpulse  float64
+	TangentImpulse [2]float64
+}
+
+// Build collects the bodies touched by the contacts and precomputes every contact row.
+// Contacts between two static bodies are dropped
+func Build(contacts []contact.Contact, objectPool *[]objects.Object) ([]Body, []Contact) {
+	bodies := make([]Body, 0, len(contacts))
+	rows := make([]Contact, 0, len(contacts))
+	// pool index -> index in bodies
+	slots := make(map[int]int, len(contacts))
+
+	addBody := func(id int) (int, bool) {
+		if slot, ok := slots[id]; ok {
+			return slot, true
+		}
+
+		obj := (*objectPool)[id]
+		velocity, errVel := obj.GetVelocity()
+		rotation, errRot := obj.GetRotation()
+		if errVel != nil || errRot != nil {
+			log.Printf("Solver: failed to get the velocity of %s: %v, %v", obj.GetId(), errVel, errRot)
+			return 0, false
+		}
+
+		omega := rotation.Radians()
+		bodies = append(bodies, Body{
+			Object:        obj,
+			InvMass:       obj.GetInverseMass(),
+			InvInertia:    obj.GetWorldInverseInertia(),
+			Velocity:      *velocity,
+			Omega:         *omega,
+			StartVelocity: *velocity,
+			StartOmega:    *omega,
+		})
+		slots[id] = len(bodies) - 1
+		return len(bodies) - 1, true
+	}
+
+	for _, c := range contacts {
+		objA := (*objectPool)[c.AID]
+		objB := (*objectPool)[c.BID]
+		if objA.GetInverseMass() == 0 && objB.GetInverseMass() == 0 {
+			continue
+		}
+
+		posA, errA := objA.GetPosition()
+		posB, errB := objB.GetPosition()
+		if errA != nil || errB != nil {
+			log.Printf("Solver: failed to get the positions of %s and %s: %v, %v", objA.GetId(), objB.GetId(), errA, errB)
+			continue
+		}
+
+		a, okA := addBody(c.AID)
+		b, okB := addBody(c.BID)
+		if !okA || !okB {
+			continue
+		}
+
+		rows = append(rows, newContact(c, a, b, *posA, *posB, bodies))
+	}
+
+	return bodies, rows
+}
+
+func newContact(c contact.Contact, a, b int, posA, posB vector.Vector3D, bodies []Body) Contact {
+	bodyA := &bodies[a]
+	bodyB := &bodies[b]
+
+	// the impulse is applied in the middle between the witness points
+	contactPoint := c.PointA.Add(c.PointB).Mul(0.5)
+	tangent1, tangent2 := c.Tangents()
+
+	row := Contact{
+		A:        a,
+		B:        b,
+		Feature:  c.Feature,
+		Normal:   c.Normal,
+		Tangents: [2]vector.Vector3D{tangent1, tangent2},
+		RA:       *contactPoint.Sub(posA),
+		RB:       *contactPoint.Sub(posB),
+		Depth:    c.Depth,
+		Material: objects.CombineMaterials(bodyA.Object.GetMaterial(), bodyB.Object.GetMaterial()),
+	}
+
+	row.NormalMass = 1 / row.EffectiveMassInverse(row.Normal, bodies)
+	for t := range row.Tangents {
+		row.TangentMass[t] = 1 / row.EffectiveMassInverse(row.Tangents[t], bodies)
+	}
+	row.Approach = row.RelativeVelocity(bodies).Dot(row.Normal)
+
+	return row
+}
+
+// EffectiveMassInverse along the direction: 1/m_A + 1/m_B + d * ((I_A^-1 (rA x d)) x rA) + d * ((I_B^-1 (rB x d)) x rB)
+func (c *Contact) EffectiveMassInverse(direction vector.Vector3D, bodies []Body) float64 {
+	bodyA := &bodies[c.A]
+	bodyB := &bodies[c.B]
+
+	return bodyA.InvMass + bodyB.InvMass +
+		direction.Dot(*bodyA.InvInertia.MulVector(*c.RA.Cross(direction)).Cross(c.RA)) +
+		direction.Dot(*bodyB.InvInertia.MulVector(*c.RB.Cross(direction)).Cross(c.RB))
+}
+
+// RelativeVelocity of the contact point of B against the one of A
+func (c *Contact) RelativeVelocity(bodies []Body) *vector.Vector3D {
+	bodyA := &bodies[c.A]
+	bodyB := &bodies[c.B]
+
+	pointVelA := bodyA.Velocity.Add(*bodyA.Omega.Cross(c.RA))
+	pointVelB := bodyB.Velocity.Add(*bodyB.Omega.Cross(c.RB))
+	return pointVelB.Sub(*pointVelA)
+}
+
+// ApplyImpulse pushes B along the impulse and A against it, static bodies are never written
+func (c *Contact) ApplyImpulse(impulse vector.Vector3D, bodies []Body) {
+	bodyA := &bodies[c.A]
+	bodyB := &bodies[c.B]
+
+	if bodyA.InvMass > 0 {
+		bodyA.Velocity = *bodyA.Velocity.Sub(*impulse.Mul(bodyA.InvMass))
+		bodyA.Omega = *bodyA.Omega.Sub(*bodyA.InvInertia.MulVector(*c.RA.Cross(impulse)))
+	}
+	if bodyB.InvMass > 0 {
+		bodyB.Velocity = *bodyB.Velocity.Add(*impulse.Mul(bodyB.InvMass))
+		bodyB.Omega = *bodyB.Omega.Add(*bodyB.InvInertia.MulVector(*c.RB.Cross(impulse)))
+	}
+}
+
+// BaumgarteVelocity is the separating velocity that pushes the part bias of the penetration
+// deeper than SLOP out in dt seconds
+func BaumgarteVelocity(depth, bias, dt float64) float64 {
+	return bias * math.Max(depth-SLOP, 0) / dt
+}
+
+// SetTargets sets the target normal velocity of every contact for a step:
+// approaching bodies bounce back with the restitution of the pair,
+// deep ones are pushed apart at the Baumgarte velocity, whichever is larger
+func SetTargets(contacts []Contact, bias float64) {
+	for i := range contacts {
+		c := &contacts[i]
+		if c.Approach < -RESTITUTION_THRESHOLD {
+			c.Target = -c.Material.Restitution * c.Approach
+		}
+		c.Target = math.Max(c.Target, BaumgarteVelocity(c.Depth, bias, constants.TimeStep))
+	}
+}
+
+// SolveNormal drives the normal relative velocity to the target,
+// the accumulated impulse can only push the bodies apart
+func (c *Contact) SolveNormal(target float64, bodies []Body) {
+	normalVelocity := c.RelativeVelocity(bodies).Dot(c.Normal)
+	normalImpulse := math.Max(c.NormalImpulse+(target-normalVelocity)*c.NormalMass, 0)
+
+	c.ApplyImpulse(*c.Normal.Mul(normalImpulse - c.NormalImpulse), bodies)
+	c.NormalImpulse = normalImpulse
+}
+
+// SolveFriction first tries to stop the sliding completely, if that needs more than the static
+// limit the contact slides and the impulse is limited by the dynamic friction (Coulomb cone)
+func (c *Contact) SolveFriction(bodies []Body) {
+	relative := c.RelativeVelocity(bodies)
+
+	stick := [2]float64{}
+	for t := range c.Tangents {
+		stick[t] = c.TangentImpulse[t] - relative.Dot(c.Tangents[t])*c.TangentMass[t]
+	}
+
+	if magnitude := math.Hypot(stick[0], stick[1]); magnitude > c.Material.StaticFriction*c.NormalImpulse {
+		scale := c.Material.DynamicFriction * c.NormalImpulse / magnitude
+		stick[0] *= scale
+		stick[1] *= scale
+	}
+
+	for t := range c.Tangents {
+		c.ApplyImpulse(*c.Tangents[t].Mul(stick[t] - c.TangentImpulse[t]), bodies)
+		c.TangentImpulse[t] = stick[t]
+	}
+}
+
+// WriteBack applies the solved velocities to the objects
+func WriteBack(bodies []Body) {
+	for _, body := range bodies {
+		if body.InvMass == 0 {
+			continue
+		}
+
+		if err := body.Object.ApplyVelocity(body.Velocity); err != nil {
+			log.Printf("Solver: failed to apply the velocity to %s: %v", body.Object.GetId(), err)
+		}
+		// ApplyRotation adds to the rotation, so only the change is passed
+		if err := body.Object.ApplyRotation(*vector.AngleFromRadians(*body.Omega.Sub(body.StartOmega))); err != nil {
+			log.Printf("Solver: failed to apply the rotation to %s: %v", body.Object.GetId(), err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package resolving
+
+import (
+	"BachelorThesis/engine/collision/contact"
+	lcp "BachelorThesis/engine/collision/resolving/LCP"
+	msi "BachelorThesis/engine/collision/resolving/MSI"
+	pgs "BachelorThesis/engine/collision/resolving/PGS"
+	tgs "BachelorThesis/engine/collision/resolving/TGS"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"log"
+)
+
+// Resolve dispatches a colliding pair to the chosen resolve algorithm. Global solvers only
+// gather the pair here and resolve everything in Solve
+func Resolve(aID, bID int, objectPool *[]objects.Object, resolveAlgorithm string) {
+	if oracleEnabled(resolveAlgorithm) {
+		lcp.ObservePair(aID, bID, objectPool)
+	}
+
+	switch resolveAlgorithm {
+	case constants.PGS:
+		switch constants.ResolveAlgoType {
+		// both solvers gather the same contacts, they differ in Solve
+		case constants.N, constants.PNT:
+			pgs.AddPair(aID, bID, objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.TGS:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			tgs.AddPair(aID, bID, objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.MSI:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			msi.AddPair(aID, bID, objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.LCP:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			lcp.AddPair(aID, bID, objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	// if there is no resolve algorithm just return
+	case constants.NoAlgo:
+		return
+	default:
+		log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
+	}
+}
+
+// ResolveContact is the same as Resolve, but the narrow phase already knows the contact
+func ResolveContact(c *contact.Contact, objectPool *[]objects.Object, resolveAlgorithm string) {
+	if oracleEnabled(resolveAlgorithm) {
+		lcp.ObserveContact(c)
+	}
+
+	switch resolveAlgorithm {
+	case constants.PGS:
+		switch constants.ResolveAlgoType {
+		// both solvers gather the same contacts, they differ in Solve
+		case constants.N, constants.PNT:
+			pgs.AddContact(c)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.TGS:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			tgs.AddContact(c)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.MSI:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			msi.AddContact(c)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.LCP:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			lcp.AddContact(c)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	// if there is no resolve algorithm just return
+	case constants.NoAlgo:
+		return
+	default:
+		log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
+	}
+}
+
+// ResolveManifold merges the manifold with the points the pair had in the previous frame
+// and resolves every point of it as a contact
+func ResolveManifold(m *contact.Manifold, objectPool *[]objects.Object, resolveAlgorithm string) {
+	if resolveAlgorithm == constants.NoAlgo {
+		return
+	}
+
+	contact.Persist(m, objectPool)
+
+	contacts := m.Contacts()
+	for i := range contacts {
+		ResolveContact(&contacts[i], objectPool, resolveAlgorithm)
+	}
+}
+
+// Solve runs the global solvers on the contacts gathered during the step, it is called once
+// after the narrow phases are done
+func Solve(objectPool *[]objects.Object, resolveAlgorithm string) {
+	if oracleEnabled(resolveAlgorithm) {
+		// the exact answer is found first, the resolver changes the velocities
+		exact := lcp.Exact(objectPool)
+		defer lcp.LogDrift(exact, objectPool, resolveAlgorithm)
+	}
+
+	switch resolveAlgorithm {
+	case constants.PGS:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			pgs.PGSNoParallel(objectPool)
+		case constants.PNT:
+			pgs.PGSParallelNonTrivial(objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.TGS:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			tgs.TGSNoParallel(objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.MSI:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			msi.MSINoParallel(objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	case constants.LCP:
+		switch constants.ResolveAlgoType {
+		case constants.N:
+			lcp.LCPNoParallel(objectPool)
+		default:
+			log.Panicf("Unknown resolve algorithm type: %s", constants.ResolveAlgoType)
+		}
+
+	// if there is no resolve algorithm just return
+	case constants.NoAlgo:
+		return
+	default:
+		log.Panicf("Unknown resolve algorithm: %s", resolveAlgorithm)
+	}
+}
+
+// oracleEnabled tells if the exact LCP solution is computed next to the chosen resolver
+func oracleEnabled(resolveAlgorithm string) bool {
+	return constants.LCPOracle && resolveAlgorithm != constants.LCP && resolveAlgorithm != constants.NoAlgo
+}
@@ -0,0 +1,207 @@
+// Package verify checks the broad phases against a brute-force test of all pairs
+package verify
+
+import (
+	"BachelorThesis/engine/collision/detection"
+	bvh "BachelorThesis/engine/collision/detection/BVH"
+	"BachelorThesis/engine/collision/detection/SaP"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/profiling"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Variant is a broad phase in one of the parallelism variants it is implemented in
+type Variant struct {
+	Algorithm string
+	Type      string
+}
+
+func (v Variant) String() string {
+	return v.Algorithm + v.Type
+}
+
+var Variants = []Variant{
+	{constants.SaP, constants.N},
+	{constants.SaP, constants.PT},
+	{constants.SaP, constants.PNT},
+	{constants.BVH, constants.N},
+	{constants.BVH, constants.PT},
+}
+
+// MAX_LOGGED_PAIRS is how many pairs of every kind a failed check names in the log
+const MAX_LOGGED_PAIRS = 5
+
+// Report is the difference between the pairs of a variant and the reference ones
+type Report struct {
+	Variant Variant
+	// number of the reference pairs
+	Pairs    int
+	Missed   []detection.Candidate
+	Spurious []detection.Candidate
+	// pairs the variant found more than once, the narrow phase would resolve them twice
+	Repeated []detection.Candidate
+}
+
+func (r Report) OK() bool {
+	return len(r.Missed) == 0 && len(r.Spurious) == 0 && len(r.Repeated) == 0
+}
+
+func (r Report) String() string {
+	if r.OK() {
+		return fmt.Sprintf("%s: all %d pairs", r.Variant, r.Pairs)
+	}
+
+	text := fmt.Sprintf("%s: %d missed, %d spurious, %d repeated of %d pairs",
+		r.Variant, len(r.Missed), len(r.Spurious), len(r.Repeated), r.Pairs)
+	for _, kind := range []struct {
+		name  string
+		pairs []detection.Candidate
+	}{{"missed", r.Missed}, {"spurious", r.Spurious}, {"repeated", r.Repeated}} {
+		if len(kind.pairs) > 0 {
+			text += fmt.Sprintf(", %s %s", kind.name, listPairs(kind.pairs))
+		}
+	}
+	return text
+}
+
+// AllPairs is the reference broad phase: every pair whose swept bounding boxes overlap, touching ones included.
+// Every broad phase works on the swept boxes, so CCD bodies meet what they passed during the frame
+func AllPairs(objectPool []objects.Object) map[detection.Candidate]bool {
+	boxes := make([]*objects.BoundingBox, len(objectPool))
+	for i, obj := range objectPool {
+		var err error
+		boxes[i], err = obj.GetSweptBoundingBox()
+		if err != nil {
+			log.Printf("Warning: failed to get bounding box for object %s: %v", obj.GetId(), err)
+			boxes[i] = nil
+		}
+	}
+
+	pairs := make(map[detection.Candidate]bool)
+	for a := range objectPool {
+		if boxes[a] == nil {
+			continue
+		}
+		for b := a + 1; b < len(objectPool); b++ {
+			if boxes[b] != nil && overlaps(boxes[a], boxes[b]) {
+				pairs[detection.NewCandidate(objectPool[a].GetId(), objectPool[b].GetId())] = true
+			}
+		}
+	}
+	return pairs
+}
+
+func overlaps(a, b *objects.BoundingBox) bool {
+	return a.Min.X <= b.Max.X && b.Min.X <= a.Max.X &&
+		a.Min.Y <= b.Max.Y && b.Min.Y <= a.Max.Y &&
+		a.Min.Z <= b.Max.Z && b.Min.Z <= a.Max.Z
+}
+
+// Check runs the broad phase variant on the pool and compares its pairs with the reference ones.
+// The pairs are only collected, nothing is resolved. SaP sorts the pool on the way
+func Check(objectPool *[]objects.Object, variant Variant) Report {
+	previousType := constants.AlgoType
+	constants.AlgoType = variant.Type
+	defer func() { constants.AlgoType = previousType }()
+
+	detection.Record()
+	switch variant.Algorithm {
+	case constants.SaP:
+		SaP.Collision(objectPool, constants.NoAlgo, constants.NoAlgo)
+	case constants.BVH:
+		bvh.Collision(objectPool, constants.NoAlgo, constants.NoAlgo)
+	default:
+		detection.Recorded()
+		log.Panicf("Unknown algorithm: %s", variant.Algorithm)
+	}
+	found := detection.Recorded()
+
+	reference := AllPairs(*objectPool)
+	report := Report{Variant: variant, Pairs: len(reference)}
+
+	seen := make(map[detection.Candidate]bool, len(found))
+	for _, pair := range found {
+		switch {
+		case seen[pair]:
+			report.Repeated = append(report.Repeated, pair)
+		case !reference[pair]:
+			report.Spurious = append(report.Spurious, pair)
+		}
+		seen[pair] = true
+	}
+	for pair := range reference {
+		if !seen[pair] {
+			report.Missed = append(report.Missed, pair)
+		}
+	}
+
+	sortPairs(report.Missed)
+	sortPairs(report.Spurious)
+	sortPairs(report.Repeated)
+	return report
+}
+
+// CheckAll checks every variant
+func CheckAll(objectPool *[]objects.Object) []Report {
+	reports := make([]Report, len(Variants))
+	for i, variant := range Variants {
+		reports[i] = Check(objectPool, variant)
+	}
+	return reports
+}
+
+var steps int
+
+// Step checks every variant every constants.VerifyBroadPhase steps and logs the result.
+// The checks are not profiled, the stage timings stay those of the simulation
+func Step(objectPool *[]objects.Object) {
+	if constants.VerifyBroadPhase <= 0 {
+		return
+	}
+	steps++
+	if steps%constants.VerifyBroadPhase != 0 {
+		return
+	}
+
+	if profiling.Enabled() {
+		profiling.Enable(false)
+		defer profiling.Enable(true)
+	}
+
+	failed := make([]string, 0)
+	for _, report := range CheckAll(objectPool) {
+		if !report.OK() {
+			failed = append(failed, report.String())
+		}
+	}
+
+	if len(failed) == 0 {
+		log.Printf("Broad phase check, step %d: every variant agrees with the brute force, %d objects", steps, len(*objectPool))
+		return
+	}
+	log.Printf("Broad phase check, step %d: %s", steps, strings.Join(failed, "; "))
+}
+
+func sortPairs(pairs []detection.Candidate) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+}
+
+func listPairs(pairs []detection.Candidate) string {
+	names := make([]string, 0, MAX_LOGGED_PAIRS)
+	for _, pair := range pairs[:min(len(pairs), MAX_LOGGED_PAIRS)] {
+		names = append(names, pair.A+"-"+pair.B)
+	}
+	if len(pairs) > MAX_LOGGED_PAIRS {
+		names = append(names, "...")
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,111 @@
+package verify
+
+import (
+	"BachelorThesis/engine/collision/detection"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"testing"
+)
+
+func TestAllPairs(t *testing.T) {
+	half := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	cases := []struct {
+		name string
+		b    vector.Vector3D
+		want bool
+	}{
+		{"overlap", vector.Vector3D{X: 1, Y: -1, Z: 0.5}, true},
+		{"touching faces", vector.Vector3D{X: 2}, true},
+		{"touching corners", vector.Vector3D{X: -2, Y: 2, Z: 2}, true},
+		{"apart in x", vector.Vector3D{X: 2.01}, false},
+		{"apart in y", vector.Vector3D{Y: -3}, false},
+		{"apart in z", vector.Vector3D{X: 1, Y: 1, Z: 2.5}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := []objects.Object{
+				objectstest.Box("a", vector.Vector3D{}, half),
+				objectstest.Box("b", tc.b, half),
+			}
+
+			pairs := AllPairs(pool)
+			if got := pairs[detection.NewCandidate("b", "a")]; got != tc.want || len(pairs) > 1 {
+				t.Errorf("pairs %v, want the pair %v", pairs, tc.want)
+			}
+		})
+	}
+}
+
+// boxes that only touch in x are a pair for every variant, as for the reference
+func TestVariantsTouchingInX(t *testing.T) {
+	half := vector.Vector3D{X: 1, Y: 1, Z: 1}
+	pool := []objects.Object{
+		objectstest.Box("a", vector.Vector3D{}, half),
+		objectstest.Box("b", vector.Vector3D{X: 2, Y: 0.5, Z: -0.5}, half),
+	}
+
+	for _, report := range CheckAll(&pool) {
+		if !report.OK() || report.Pairs != 1 {
+			t.Errorf("%s", report)
+		}
+	}
+}
+
+// a CCD body that passed through a wall during the frame pairs with it in every variant
+func TestVariantsSweptCCD(t *testing.T) {
+	bullet := objectstest.Sphere("bullet", vector.Vector3D{X: -10}, 0.5)
+	bullet.SetPosition(vector.Vector3D{X: 10})
+	bullet.Update(0)
+	bullet.SetCCD(true)
+	pool := []objects.Object{
+		bullet,
+		objectstest.Box("wall", vector.Vector3D{}, vector.Vector3D{X: 0.1, Y: 2, Z: 2}),
+	}
+
+	for _, report := range CheckAll(&pool) {
+		if !report.OK() || report.Pairs != 1 {
+			t.Errorf("%s", report)
+		}
+	}
+}
+
+// every variant finds the same pairs as the brute force, in crowded scenes and in sparse ones
+func TestVariantsAgree(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		pool := objectstest.RandomSpheres(2048, seed)
+		for _, report := range CheckAll(&pool) {
+			if report.Pairs == 0 {
+				t.Fatalf("seed %d: the scene has no pairs to check", seed)
+			}
+			if !report.OK() {
+				t.Errorf("seed %d: %s", seed, report)
+			}
+		}
+	}
+
+	sparse := []objects.Object{
+		objectstest.Sphere("lonely", vector.Vector3D{}, 1),
+		objectstest.Sphere("far", vector.Vector3D{X: 100, Y: 100, Z: 100}, 1),
+	}
+	for _, report := range CheckAll(&sparse) {
+		if !report.OK() || report.Pairs != 0 {
+			t.Errorf("sparse scene: %s", report)
+		}
+	}
+}
+
+func TestReportString(t *testing.T) {
+	report := Report{
+		Variant:  Variants[0],
+		Pairs:    10,
+		Missed:   []detection.Candidate{{A: "a", B: "b"}},
+		Repeated: []detection.Candidate{{A: "c", B: "d"}, {A: "c", B: "e"}},
+	}
+
+	want := Variants[0].String() + ": 1 missed, 0 spurious, 2 repeated of 10 pairs, missed a-b, repeated c-d c-e"
+	if got := report.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
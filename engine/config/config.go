@@ -0,0 +1,283 @@
+package config
+
+import (
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/scene"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config is a whole experiment: the algorithms of every stage and the scene.
+// Algorithms and variants are short names, see the tables below
+type Config struct {
+	Broad          string `json:"broad"`
+	BroadVariant   string `json:"broad_variant"`
+	Narrow         string `json:"narrow"`
+	NarrowVariant  string `json:"narrow_variant"`
+	Resolve        string `json:"resolve"`
+	ResolveVariant string `json:"resolve_variant"`
+	// the LCP oracle compares the other resolvers with the exact solution
+	LCPOracle  bool   `json:"lcp_oracle"`
+	Integrator string `json:"integrator"`
+	// every that many steps the pairs of every broad phase variant are compared with all pairs, 0 is off
+	VerifyBroadPhase int `json:"verify_broad_phase"`
+
+	// "sequential" or "parallel"
+	Pipeline string `json:"pipeline"`
+	WorldBox bool   `json:"world_box"`
+
+	// 0 picks a random seed
+	Seed    int64 `json:"seed"`
+	Objects int   `json:"objects"`
+	// 0 is no limit, the visualizer runs until its window is closed then.
+	// Frames only limit headless runs
+	Duration Duration `json:"duration"`
+	Frames   int      `json:"frames"`
+	Headless bool     `json:"headless"`
+}
+
+// Duration is a time.Duration written like "30s" in the config file and in the flags
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) Set(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	return d.Set(value)
+}
+
+// stage is an algorithm and the variants it is implemented in
+type stage struct {
+	name     string
+	variants []string
+}
+
+var (
+	variants = map[string]string{
+		"n":   constants.N,
+		"pt":  constants.PT,
+		"pnt": constants.PNT,
+	}
+
+	broadPhases = map[string]stage{
+		"sap": {constants.SaP, []string{"n", "pt", "pnt"}},
+		"bvh": {constants.BVH, []string{"n", "pt"}},
+	}
+	narrowPhases = map[string]stage{
+		"sat": {constants.SAT, []string{"n", "pt"}},
+		"gjk": {constants.GJK, []string{"n"}},
+		"epa": {constants.EPA, []string{"n"}},
+	}
+	resolvers = map[string]stage{
+		"pgs": {constants.PGS, []string{"n", "pnt"}},
+		"tgs": {constants.TGS, []string{"n"}},
+		"msi": {constants.MSI, []string{"n"}},
+		"lcp": {constants.LCP, []string{"n"}},
+	}
+	integrators = map[string]string{
+		"euler":  constants.Euler,
+		"verlet": constants.Verlet,
+		"rk4":    constants.RK4,
+	}
+	pipelines = map[string]string{
+		"sequential": constants.SequentialPipeline,
+		"parallel":   constants.ParallelPipeline,
+	}
+)
+
+// DEFAULT_OBJECTS is the number of spheres the visualizer always started with
+const DEFAULT_OBJECTS = 1024
+
+func Default() Config {
+	return Config{
+		Broad:          "sap",
+		BroadVariant:   "n",
+		Narrow:         "sat",
+		NarrowVariant:  "n",
+		Resolve:        "pgs",
+		ResolveVariant: "n",
+		Integrator:     "euler",
+		Pipeline:       "sequential",
+		Objects:        DEFAULT_OBJECTS,
+	}
+}
+
+// Load reads a JSON config, the fields it doesn't set keep their defaults
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.Broad, "broad", c.Broad, "broad phase: "+keys(broadPhases))
+	fs.StringVar(&c.BroadVariant, "broad-variant", c.BroadVariant, "broad phase parallelism: "+keys(variants))
+	fs.StringVar(&c.Narrow, "narrow", c.Narrow, "narrow phase: "+keys(narrowPhases))
+	fs.StringVar(&c.NarrowVariant, "narrow-variant", c.NarrowVariant, "narrow phase parallelism: "+keys(variants))
+	fs.StringVar(&c.Resolve, "resolve", c.Resolve, "resolver: "+keys(resolvers))
+	fs.StringVar(&c.ResolveVariant, "resolve-variant", c.ResolveVariant, "resolver parallelism: "+keys(variants))
+	fs.BoolVar(&c.LCPOracle, "lcp-oracle", c.LCPOracle, "compare the resolver with the exact LCP solution")
+	fs.StringVar(&c.Integrator, "integrator", c.Integrator, "integrator: "+keys(integrators))
+	fs.IntVar(&c.VerifyBroadPhase, "verify-broad", c.VerifyBroadPhase, "every that many steps diff the pairs of every broad phase variant with a brute-force check, 0 for never")
+	fs.StringVar(&c.Pipeline, "pipeline", c.Pipeline, "pipeline: "+keys(pipelines))
+	fs.BoolVar(&c.WorldBox, "worldbox", c.WorldBox, "enclose the scene in a world box")
+	fs.Int64Var(&c.Seed, "seed", c.Seed, "seed of the scene, 0 for a random one")
+	fs.IntVar(&c.Objects, "objects", c.Objects, "number of spheres at the start")
+	fs.Var(&c.Duration, "duration", "wall-clock length of the run, 0 for no limit")
+	fs.IntVar(&c.Frames, "frames", c.Frames, "number of frames of a headless run, 0 for no limit")
+	fs.BoolVar(&c.Headless, "headless", c.Headless, "run without the visualizer")
+}
+
+// Parse reads the config file given by -config and then the other flags, which override it.
+// Without arguments it returns interactive = true, the caller asks the user instead
+func Parse(name string, args []string) (cfg Config, interactive bool, err error) {
+	if len(args) == 0 {
+		return Default(), true, nil
+	}
+
+	// the first pass only finds the config file, the flags are read over it in the second one
+	path := ""
+	first := flag.NewFlagSet(name, flag.ContinueOnError)
+	first.SetOutput(io.Discard)
+	scratch := Default()
+	scratch.register(first)
+	first.StringVar(&path, "config", "", "")
+	if err := first.Parse(args); err != nil && err != flag.ErrHelp {
+		return cfg, false, err
+	}
+
+	cfg = Default()
+	if path != "" {
+		if cfg, err = Load(path); err != nil {
+			return cfg, false, err
+		}
+	}
+
+	second := flag.NewFlagSet(name, flag.ContinueOnError)
+	cfg.register(second)
+	second.String("config", path, "JSON config file, the other flags override it")
+	if err := second.Parse(args); err != nil {
+		return cfg, false, err
+	}
+	if second.NArg() > 0 {
+		return cfg, false, fmt.Errorf("unexpected arguments: %v", second.Args())
+	}
+
+	return cfg, false, cfg.Validate()
+}
+
+// Validate checks that every algorithm exists in the chosen variant
+func (c Config) Validate() error {
+	if err := checkStage("broad phase", broadPhases, c.Broad, c.BroadVariant); err != nil {
+		return err
+	}
+	if err := checkStage("narrow phase", narrowPhases, c.Narrow, c.NarrowVariant); err != nil {
+		return err
+	}
+	if err := checkStage("resolver", resolvers, c.Resolve, c.ResolveVariant); err != nil {
+		return err
+	}
+	if _, ok := integrators[c.Integrator]; !ok {
+		return fmt.Errorf("unknown integrator %q, expected %s", c.Integrator, keys(integrators))
+	}
+	if _, ok := pipelines[c.Pipeline]; !ok {
+		return fmt.Errorf("unknown pipeline %q, expected %s", c.Pipeline, keys(pipelines))
+	}
+	if c.Objects < 0 || c.Frames < 0 || c.Duration < 0 || c.VerifyBroadPhase < 0 {
+		return fmt.Errorf("objects, frames, duration and verify-broad can not be negative")
+	}
+	if c.LCPOracle && c.Resolve == "lcp" {
+		return fmt.Errorf("the LCP oracle compares other resolvers with LCP, it can't check LCP itself")
+	}
+	return nil
+}
+
+func checkStage(what string, stages map[string]stage, name, variant string) error {
+	s, ok := stages[name]
+	if !ok {
+		return fmt.Errorf("unknown %s %q, expected %s", what, name, keys(stages))
+	}
+	for _, v := range s.variants {
+		if v == variant {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s has no variant %q, expected %s", what, name, variant, strings.Join(s.variants, ", "))
+}
+
+// BroadVariants, NarrowVariants and ResolveVariants list the parallelism variants
+// an algorithm is implemented in, nil for an unknown one
+func BroadVariants(name string) []string {
+	return broadPhases[name].variants
+}
+
+func NarrowVariants(name string) []string {
+	return narrowPhases[name].variants
+}
+
+func ResolveVariants(name string) []string {
+	return resolvers[name].variants
+}
+
+// Apply sets the global options of the engine and the seed of the scene and returns
+// the algorithm names engine.Run and headless.Run take. The config has to be valid
+func (c Config) Apply() (algorithm, secondaryAlgorithm, resolveAlgorithm, integrator string) {
+	constants.AlgoType = variants[c.BroadVariant]
+	constants.SecondaryAlgoType = variants[c.NarrowVariant]
+	constants.ResolveAlgoType = variants[c.ResolveVariant]
+	constants.Pipeline = pipelines[c.Pipeline]
+	constants.WorldBox = c.WorldBox
+	constants.LCPOracle = c.LCPOracle
+	constants.VerifyBroadPhase = c.VerifyBroadPhase
+
+	if c.Seed != 0 {
+		scene.Seed(c.Seed)
+	}
+
+	return broadPhases[c.Broad].name, narrowPhases[c.Narrow].name, resolvers[c.Resolve].name, integrators[c.Integrator]
+}
+
+// keys lists the names of a table for the flag help and the errors
+func keys[T any](table map[string]T) string {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -1,9 +1,20 @@
 package constants
 
+import "BachelorThesis/engine/vector"
+
 const (
 	// Window
 	WindowWidth  = 1080
 	WindowHeight = 720
+
+	// World box, half of its size
+	WorldSize = 30
+
+	// Simulation step in seconds, the simulation always advances by it
+	TimeStep = 1.0 / 60
+	// a slow frame runs at most that many steps, after that the simulation falls behind
+	// the real time instead of taking even longer to catch up
+	MaxStepsPerFrame = 5
 )
 
 const (
@@ -21,6 +32,13 @@ const (
 	EPA = "Expanding Polytope Algorithm"
 )
 
+const (
+	// Integrator names
+	Euler  = "Semi-implicit Euler"
+	Verlet = "Velocity Verlet"
+	RK4    = "Runge-Kutta 4"
+)
+
 const (
 	N   = " (No Parallel)"
 	PT  = " (Parallel trivial)"
@@ -35,4 +53,16 @@ var (
 	SecondaryAlgoType = N
 	ResolveAlgoType   = N
 	Pipeline          = SequentialPipeline
+	WorldBox          = false
+	// compare the resolver with the exact LCP solution every step
+	LCPOracle = false
+	// every that many steps the pairs of every broad phase variant are compared
+	// with a brute-force test of all pairs, 0 is off
+	VerifyBroadPhase = 0
+
+	// acceleration of every dynamic body, m/s^2. The demo scene has no floor, so there is none by default
+	Gravity = vector.Vector3D{}
+	// damping of new bodies, the part of the velocity lost per second
+	LinearDamping  = 0.0
+	AngularDamping = 0.05
 )
@@ -3,6 +3,7 @@ package engine
 import (
 	"BachelorThesis/engine/constants"
 	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/scene"
 	st "BachelorThesis/engine/singletone"
 	"BachelorThesis/engine/visualizer"
 	"context"
@@ -11,8 +12,8 @@ import (
 
 var singletone *st.Engine
 
-func Run(algorithm, secondaryAlgorithm, resolveAlgorithm string, ctx context.Context, cancel context.CancelFunc) {
-	log.Printf("Simulation of %s%s + %s%s + %s%s started", algorithm, constants.AlgoType, secondaryAlgorithm, constants.SecondaryAlgoType, resolveAlgorithm, constants.ResolveAlgoType)
+func Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator string, spheres int, ctx context.Context, cancel context.CancelFunc) {
+	log.Printf("Simulation of %s%s + %s%s + %s%s with %s started", algorithm, constants.AlgoType, secondaryAlgorithm, constants.SecondaryAlgoType, resolveAlgorithm, constants.ResolveAlgoType, integrator)
 
 	if algorithm == constants.NoAlgo {
 		log.Printf("Secondary algorithm is not specified, must be an error")
@@ -26,10 +27,12 @@ func Run(algorithm, secondaryAlgorithm, resolveAlgorithm string, ctx context.Con
 
 	pool := make([]objects.Object, 0)
 
-	singletone = st.NewEngine(algorithm, secondaryAlgorithm, resolveAlgorithm, &pool, ctx)
+	singletone = st.NewEngine(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, &pool, ctx)
+
+	scene.AddWorldBox(singletone)
 	go singletone.StartEngineLoop()
 
-	visualizer.Start(singletone, cancel)
+	visualizer.Start(singletone, spheres, cancel)
 
-	log.Printf("Simulation of %s%s + %s%s + %s%s ended", algorithm, constants.AlgoType, secondaryAlgorithm, constants.SecondaryAlgoType, resolveAlgorithm, constants.ResolveAlgoType)
+	log.Printf("Simulation of %s%s + %s%s + %s%s with %s ended", algorithm, constants.AlgoType, secondaryAlgorithm, constants.SecondaryAlgoType, resolveAlgorithm, constants.ResolveAlgoType, integrator)
 }
@@ -0,0 +1,111 @@
+package headless
+
+import (
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/integration"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/profiling"
+	"BachelorThesis/engine/scene"
+	st "BachelorThesis/engine/singletone"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Options limit a headless run. Zero means no limit, but a run needs Frames or Duration
+type Options struct {
+	// spheres in the scene, the world box is added if constants.WorldBox is set
+	Objects int
+	// every frame is one step of constants.TimeStep
+	Frames int
+	// wall-clock budget, the frame that exceeds it is the last one
+	Duration time.Duration
+
+	// frames run before the measured ones, they count to none of the limits and results
+	Warmup int
+	// measure the time of every stage, see profiling
+	Profile bool
+}
+
+type Result struct {
+	Frames  int
+	Objects int
+	Elapsed time.Duration
+	// energy at the start and at the end, see integration.Energy
+	StartEnergy float64
+	EndEnergy   float64
+	// time of every stage over the measured frames, only with Options.Profile
+	Stages profiling.Timings
+}
+
+// FPS is the simulated frames per second of wall-clock time
+func (r Result) FPS() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Frames) / r.Elapsed.Seconds()
+}
+
+// Run simulates the scene without the visualizer, as fast as the engine can step it.
+// It stops at the limits of the options or when ctx is done
+func Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator string, options Options, ctx context.Context) (Result, error) {
+	if options.Frames <= 0 && options.Duration <= 0 {
+		return Result{}, fmt.Errorf("headless run needs a frame count or a duration")
+	}
+
+	log.Printf("Headless simulation of %s%s + %s%s + %s%s with %s started", algorithm, constants.AlgoType, secondaryAlgorithm, constants.SecondaryAlgoType, resolveAlgorithm, constants.ResolveAlgoType, integrator)
+
+	// the engine loop ends with this context, the caller's one only stops the frames
+	engineCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := make([]objects.Object, 0, options.Objects)
+	engine := st.NewEngine(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, &pool, engineCtx)
+
+	scene.AddWorldBox(engine)
+	for i := 0; i < options.Objects; i++ {
+		scene.AddSphere(engine, fmt.Sprintf("sphere_%d", i))
+	}
+
+	go engine.StartEngineLoop()
+
+	for i := 0; i < options.Warmup; i++ {
+		engine.Step(constants.TimeStep)
+	}
+
+	result := Result{
+		Objects:     len(pool),
+		StartEnergy: integration.Energy(pool, constants.Gravity),
+	}
+
+	profiling.Enable(options.Profile)
+	defer profiling.Enable(false)
+	profiling.Take()
+
+	start := time.Now()
+frames:
+	for options.Frames <= 0 || result.Frames < options.Frames {
+		if options.Duration > 0 && time.Since(start) >= options.Duration {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Headless simulation interrupted")
+			break frames
+		default:
+		}
+
+		engine.Step(constants.TimeStep)
+		result.Frames++
+	}
+	result.Elapsed = time.Since(start)
+	result.Stages = profiling.Take()
+
+	// the engine loop is idle between the steps, the pool can be read without it
+	result.EndEnergy = integration.Energy(pool, constants.Gravity)
+
+	log.Printf("Headless simulation ended: %d frames of %d objects in %v (%.1f FPS)", result.Frames, result.Objects, result.Elapsed, result.FPS())
+	return result, nil
+}
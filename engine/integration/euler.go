@@ -0,0 +1,22 @@
+package integration
+
+import (
+	"BachelorThesis/engine/objects"
+)
+
+// SemiImplicitEuler changes the velocities first and moves the body with the new ones.
+// It is first order, but it doesn't gain energy on oscillations like the explicit Euler does
+type SemiImplicitEuler struct{}
+
+func (SemiImplicitEuler) Integrate(object objects.Object, dt float64, acceleration Acceleration) {
+	start, ok := startStep(object, dt)
+	if !ok {
+		return
+	}
+
+	a, alpha := acceleration(object, start)
+	velocity := start.Velocity.Add(*a.Mul(dt))
+	omega := start.Omega.Add(*alpha.Mul(dt))
+
+	move(object, dt, *velocity, *omega, *velocity, *omega)
+}
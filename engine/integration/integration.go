@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"log"
+)
+
+// Integrator moves a body by one step: the accelerations change its velocities, the velocities move it.
+// The accelerations can depend on the motion of the body, so the integrators evaluate them
+// wherever their scheme needs them during the step
+type Integrator interface {
+	// Integrate moves the object by dt seconds and clears its forces.
+	// Static bodies only refresh their bounding box
+	Integrate(object objects.Object, dt float64, acceleration Acceleration)
+}
+
+// New returns the integrator with the given name, see the integrator names in constants
+func New(name string) Integrator {
+	switch name {
+	case constants.Euler:
+		return SemiImplicitEuler{}
+	case constants.Verlet:
+		return Verlet{}
+	case constants.RK4:
+		return RK4{}
+	default:
+		log.Panicf("Unknown integrator: %s", name)
+	}
+	return nil
+}
+
+// State is the motion of a body somewhere inside the step
+type State struct {
+	Position    vector.Vector3D
+	Velocity    vector.Vector3D
+	Orientation vector.Quaternion
+	// angular velocity in radians per second
+	Omega vector.Vector3D
+}
+
+// Acceleration returns the linear and the angular (radians per second^2) acceleration
+// of the object in the given state, damping included
+type Acceleration func(object objects.Object, state State) (vector.Vector3D, vector.Vector3D)
+
+// Forces is the acceleration the engine uses: gravity, the forces and torques applied to the body
+// for the step and its damping. The torque turns the body through its inertia in the state's orientation
+func Forces(gravity vector.Vector3D) Acceleration {
+	return func(object objects.Object, state State) (vector.Vector3D, vector.Vector3D) {
+		force := object.GetForce()
+		torque := object.GetTorque()
+		linearDamping, angularDamping := object.GetDamping()
+
+		orientation := state.Orientation.ToMatrix()
+		inverseInertia := object.GetInverseInertia()
+		worldInverseInertia := orientation.Mul(*inverseInertia.Mul(*orientation.Transpose()))
+
+		acceleration := gravity.Add(*force.Mul(object.GetInverseMass())).Sub(*state.Velocity.Mul(linearDamping))
+		angularAcceleration := worldInverseInertia.MulVector(torque).Sub(*state.Omega.Mul(angularDamping))
+		return *acceleration, *angularAcceleration
+	}
+}
+
+// startStep saves the pose the step starts from and reads the state of the object,
+// static bodies are only moved by Update and lose their forces
+func startStep(object objects.Object, dt float64) (State, bool) {
+	object.SaveStartPose()
+	if object.GetInverseMass() == 0 {
+		object.ClearForces()
+		object.Update(dt)
+		return State{}, false
+	}
+
+	position, errPos := object.GetPosition()
+	velocity, errVel := object.GetVelocity()
+	rotation, errRot := object.GetRotation()
+	if errPos != nil || errVel != nil || errRot != nil {
+		log.Printf("Error integrating object %s: %v, %v, %v", object.GetId(), errPos, errVel, errRot)
+		object.ClearForces()
+		return State{}, false
+	}
+
+	return State{
+		Position:    *position,
+		Velocity:    *velocity,
+		Orientation: object.GetQuaternion(),
+		Omega:       *rotation.Radians(),
+	}, true
+}
+
+// advance is the state after moving for dt seconds with the given velocities,
+// the velocities of the result are the ones given
+func (s State) advance(dt float64, velocity, omega vector.Vector3D) State {
+	return State{
+		Position:    *s.Position.Add(*velocity.Mul(dt)),
+		Velocity:    velocity,
+		Orientation: *vector.RotationVectorQuaternion(*omega.Mul(dt)).Mul(s.Orientation),
+		Omega:       omega,
+	}
+}
+
+// move moves the object with the given velocities for dt seconds, leaves it the final velocities
+// and clears its forces
+func move(object objects.Object, dt float64, velocity, omega, finalVelocity, finalOmega vector.Vector3D) {
+	defer object.ClearForces()
+
+	if err := setVelocities(object, velocity, omega); err != nil {
+		log.Printf("Error integrating object %s: %v", object.GetId(), err)
+		return
+	}
+	object.Update(dt)
+	if err := setVelocities(object, finalVelocity, finalOmega); err != nil {
+		log.Printf("Error integrating object %s: %v", object.GetId(), err)
+	}
+}
+
+// setVelocities sets the linear and the angular (radians per second) velocity of the object
+func setVelocities(object objects.Object, velocity, omega vector.Vector3D) error {
+	if err := object.ApplyVelocity(velocity); err != nil {
+		return err
+	}
+
+	rotation, err := object.GetRotation()
+	if err != nil {
+		return err
+	}
+	// ApplyRotation adds to the rotation, so only the change is passed
+	if err := object.ApplyRotation(*vector.AngleFromRadians(*omega.Sub(*rotation.Radians()))); err != nil {
+		return fmt.Errorf("failed to set rotation: %w", err)
+	}
+	return nil
+}
+
+// Energy is the kinetic energy of the dynamic bodies plus their potential energy in the gravity field.
+// Without forces, contacts and damping it only changes by the error of the integrator
+func Energy(pool []objects.Object, gravity vector.Vector3D) float64 {
+	energy := 0.0
+	for _, object := range pool {
+		mass := object.GetMass()
+		if mass == 0 {
+			continue
+		}
+
+		position, errPos := object.GetPosition()
+		velocity, errVel := object.GetVelocity()
+		rotation, errRot := object.GetRotation()
+		if errPos != nil || errVel != nil || errRot != nil {
+			log.Printf("Error getting energy of object %s: %v, %v, %v", object.GetId(), errPos, errVel, errRot)
+			continue
+		}
+
+		// the inertia is known in body space, so the angular velocity is turned there
+		orientation := object.GetOrientation()
+		omega := orientation.Transpose().MulVector(*rotation.Radians())
+		inertia := object.GetInertia()
+
+		energy += 0.5 * mass * velocity.LengthSq()
+		energy += 0.5 * omega.Dot(*inertia.MulVector(*omega))
+		energy -= mass * gravity.Dot(*position)
+	}
+	return energy
+}
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/objects/objectstest"
+	"BachelorThesis/engine/vector"
+	"math"
+	"testing"
+)
+
+// a unit mass on a spring to the origin, the position dependent force tells the schemes apart
+const (
+	STIFFNESS  = 10.0
+	AMPLITUDE  = 2.0
+	SPRING_RUN = 10.0
+)
+
+func spring(object objects.Object, state State) (vector.Vector3D, vector.Vector3D) {
+	return *state.Position.Mul(-STIFFNESS * object.GetInverseMass()), vector.Vector3D{}
+}
+
+// runSpring returns the largest changes of the energy and of the position from the exact motion during the run
+func runSpring(t *testing.T, integrator Integrator) (float64, float64) {
+	t.Helper()
+
+	body := objectstest.Sphere("spring", vector.Vector3D{X: AMPLITUDE}, 0.5)
+	body.SetMass(1)
+	body.SetDamping(0, 0)
+
+	energy := func() float64 {
+		position, _ := body.GetPosition()
+		velocity, _ := body.GetVelocity()
+		return 0.5*velocity.LengthSq() + 0.5*STIFFNESS*position.LengthSq()
+	}
+	start := energy()
+
+	drift, positionError := 0.0, 0.0
+	steps := int(SPRING_RUN / constants.TimeStep)
+	for i := 1; i <= steps; i++ {
+		integrator.Integrate(body, constants.TimeStep, spring)
+		drift = math.Max(drift, math.Abs(energy()-start)/start)
+
+		position, _ := body.GetPosition()
+		exact := AMPLITUDE * math.Cos(math.Sqrt(STIFFNESS)*float64(i)*constants.TimeStep)
+		positionError = math.Max(positionError, math.Abs(position.X-exact))
+	}
+	return drift, positionError
+}
+
+func TestSpringDrift(t *testing.T) {
+	eulerDrift, eulerError := runSpring(t, SemiImplicitEuler{})
+	verletDrift, verletError := runSpring(t, Verlet{})
+	rk4Drift, rk4Error := runSpring(t, RK4{})
+	t.Logf("energy drift: Euler %.3g, Verlet %.3g, RK4 %.3g", eulerDrift, verletDrift, rk4Drift)
+	t.Logf("position error: Euler %.3g, Verlet %.3g, RK4 %.3g", eulerError, verletError, rk4Error)
+
+	// the orders are 1, 2 and 4, every scheme is far better than the previous one.
+	// The phase error of the semi-implicit Euler on a spring is second order, its position is only ~9 times worse
+	if verletDrift*10 > eulerDrift || rk4Drift*10 > verletDrift {
+		t.Errorf("energy drift does not fall with the order: Euler %v, Verlet %v, RK4 %v", eulerDrift, verletDrift, rk4Drift)
+	}
+	if verletError*5 > eulerError || rk4Error*10 > verletError {
+		t.Errorf("position error does not fall with the order: Euler %v, Verlet %v, RK4 %v", eulerError, verletError, rk4Error)
+	}
+	// the symplectic schemes keep the energy bounded
+	if eulerDrift > 0.05 || verletDrift > 1e-3 {
+		t.Errorf("energy drifts too far: Euler %v, Verlet %v", eulerDrift, verletDrift)
+	}
+}
+
+// under gravity alone the motion is a parabola, Verlet and RK4 hit it exactly
+func TestProjectile(t *testing.T) {
+	gravity := vector.Vector3D{Y: -9.81}
+	velocity := vector.Vector3D{X: 3, Y: 5}
+
+	for name, integrator := range map[string]Integrator{"Verlet": Verlet{}, "RK4": RK4{}} {
+		body := objectstest.Sphere("projectile", vector.Vector3D{}, 0.5)
+		body.SetDamping(0, 0)
+		body.ApplyVelocity(velocity)
+
+		steps := 60
+		for i := 0; i < steps; i++ {
+			integrator.Integrate(body, constants.TimeStep, Forces(gravity))
+		}
+
+		time := float64(steps) * constants.TimeStep
+		exact := velocity.Mul(time).Add(*gravity.Mul(time * time / 2))
+		if position, _ := body.GetPosition(); position.Sub(*exact).Length() > 1e-9 {
+			t.Errorf("%s: position %v, want %v", name, *position, *exact)
+		}
+	}
+}
+
+// the torque turns the body through its inertia in the orientation of the stage
+func TestForcesTorque(t *testing.T) {
+	box := objectstest.Box("box", vector.Vector3D{}, vector.Vector3D{X: 2, Y: 1, Z: 0.5})
+	box.SetDamping(0, 0)
+	box.SetOrientation(*vector.AxisAngleQuaternion(vector.Vector3D{Z: 1}, math.Pi/2))
+	box.AddTorque(vector.Vector3D{X: 1})
+
+	state := State{Orientation: box.GetQuaternion()}
+	_, alpha := Forces(vector.Vector3D{})(box, state)
+
+	// turned a quarter around Z, the world X axis is the local -Y one
+	inverse := box.GetInverseInertia()
+	want := inverse[1][1]
+	if math.Abs(alpha.X-want) > 1e-9 || math.Abs(alpha.Y) > 1e-9 || math.Abs(alpha.Z) > 1e-9 {
+		t.Errorf("angular acceleration %v, want %v around X", alpha, want)
+	}
+}
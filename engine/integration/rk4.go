@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+)
+
+// RK4 is the classic fourth order Runge-Kutta scheme, the acceleration is evaluated
+// at the start, twice in the middle and at the end of the step
+type RK4 struct{}
+
+func (RK4) Integrate(object objects.Object, dt float64, acceleration Acceleration) {
+	start, ok := startStep(object, dt)
+	if !ok {
+		return
+	}
+
+	// every stage moves from the start with the velocities of the previous one
+	a1, alpha1 := acceleration(object, start)
+	stage2 := start.advance(dt/2, start.Velocity, start.Omega)
+	stage2.Velocity = *start.Velocity.Add(*a1.Mul(dt / 2))
+	stage2.Omega = *start.Omega.Add(*alpha1.Mul(dt / 2))
+
+	a2, alpha2 := acceleration(object, stage2)
+	stage3 := start.advance(dt/2, stage2.Velocity, stage2.Omega)
+	stage3.Velocity = *start.Velocity.Add(*a2.Mul(dt / 2))
+	stage3.Omega = *start.Omega.Add(*alpha2.Mul(dt / 2))
+
+	a3, alpha3 := acceleration(object, stage3)
+	stage4 := start.advance(dt, stage3.Velocity, stage3.Omega)
+	stage4.Velocity = *start.Velocity.Add(*a3.Mul(dt))
+	stage4.Omega = *start.Omega.Add(*alpha3.Mul(dt))
+
+	a4, alpha4 := acceleration(object, stage4)
+
+	// the move is the mean velocity times dt, the velocity changes by the mean acceleration
+	velocity := rk4Mean(start.Velocity, stage2.Velocity, stage3.Velocity, stage4.Velocity)
+	omega := rk4Mean(start.Omega, stage2.Omega, stage3.Omega, stage4.Omega)
+	finalVelocity := start.Velocity.Add(*rk4Mean(a1, a2, a3, a4).Mul(dt))
+	finalOmega := start.Omega.Add(*rk4Mean(alpha1, alpha2, alpha3, alpha4).Mul(dt))
+
+	move(object, dt, *velocity, *omega, *finalVelocity, *finalOmega)
+}
+
+// rk4Mean weights the stages 1, 2, 2, 1
+func rk4Mean(k1, k2, k3, k4 vector.Vector3D) *vector.Vector3D {
+	return k1.Add(*k2.Mul(2)).Add(*k3.Mul(2)).Add(k4).Mul(1.0 / 6)
+}
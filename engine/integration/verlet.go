@@ -0,0 +1,33 @@
+package integration
+
+import (
+	"BachelorThesis/engine/objects"
+)
+
+// Verlet is the velocity Verlet scheme: half of the velocity change, the move, the other half with
+// the acceleration at the new position. It is second order and keeps the energy of conservative
+// motion from drifting
+type Verlet struct{}
+
+func (Verlet) Integrate(object objects.Object, dt float64, acceleration Acceleration) {
+	start, ok := startStep(object, dt)
+	if !ok {
+		return
+	}
+
+	a, alpha := acceleration(object, start)
+	velocity := start.Velocity.Add(*a.Mul(dt / 2))
+	omega := start.Omega.Add(*alpha.Mul(dt / 2))
+
+	// the velocity at the end is not known yet, velocity dependent accelerations like
+	// the damping get the one the first half kick predicts
+	end := start.advance(dt, *velocity, *omega)
+	end.Velocity = *velocity.Add(*a.Mul(dt / 2))
+	end.Omega = *omega.Add(*alpha.Mul(dt / 2))
+	endA, endAlpha := acceleration(object, end)
+
+	finalVelocity := velocity.Add(*endA.Mul(dt / 2))
+	finalOmega := omega.Add(*endAlpha.Mul(dt / 2))
+
+	move(object, dt, *velocity, *omega, *finalVelocity, *finalOmega)
+}
@@ -0,0 +1,301 @@
+package objects
+
+import (
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+)
+
+// body is the state every shape has, shapes embed it and add their geometry
+type body struct {
+	id string
+
+	position *vector.Vector3D
+	velocity *vector.Vector3D
+
+	orientation *vector.Quaternion
+	// orientation as a matrix, the narrow phase needs it much more often than it changes
+	orientationMatrix *vector.Matrix3D
+	rotation          *vector.Angle3D
+
+	// pose before the integrator moved the body this step
+	startPosition    vector.Vector3D
+	startOrientation vector.Quaternion
+
+	mass        float64
+	inverseMass float64
+	// principal moments of inertia for the unit mass, given by the shape
+	unitInertia vector.Vector3D
+
+	volume   float64
+	material Material
+
+	// forces and torques of the current step in world space, cleared by the integrator
+	force  vector.Vector3D
+	torque vector.Vector3D
+
+	// the part of the velocity lost per second
+	linearDamping  float64
+	angularDamping float64
+
+	// continuous collision detection, for bodies fast enough to pass through others in one frame
+	ccd bool
+
+	boundingBox *BoundingBox
+}
+
+// newBody takes the shape volume and its inertia for the unit mass
+func newBody(id string, volume float64, unitInertia vector.Vector3D) body {
+	b := body{
+		id: id,
+
+		position: vector.ZeroVector(),
+		velocity: vector.ZeroVector(),
+
+		orientation:       vector.IdentityQuaternion(),
+		orientationMatrix: vector.IdentityMatrix(),
+		rotation:          vector.ZeroAngle(),
+
+		startOrientation: *vector.IdentityQuaternion(),
+
+		unitInertia: unitInertia,
+
+		volume: volume,
+
+		linearDamping:  constants.LinearDamping,
+		angularDamping: constants.AngularDamping,
+	}
+	b.SetMaterial(DefaultMaterial)
+
+	return b
+}
+
+// move applies velocity and rotation for dt seconds. Rotation is the angular velocity
+// in degrees per second around the world axes, the orientation turns around it
+func (b *body) move(dt float64) {
+	b.SetPosition(*b.position.Add(*b.velocity.Mul(dt)))
+
+	if *b.rotation != (vector.Angle3D{}) {
+		turn := vector.RotationVectorQuaternion(*b.rotation.Radians().Mul(dt))
+		b.SetOrientation(*turn.Mul(*b.orientation))
+	}
+}
+
+// SaveStartPose remembers the pose the step starts from, the integrator calls it before moving the body
+func (b *body) SaveStartPose() {
+	b.startPosition = *b.position
+	b.startOrientation = *b.orientation
+}
+
+// GetStartPose returns the position and orientation saved by SaveStartPose
+func (b *body) GetStartPose() (vector.Vector3D, vector.Quaternion) {
+	return b.startPosition, b.startOrientation
+}
+
+func (b *body) GetBoundingBox() (*BoundingBox, error) {
+	if b.boundingBox == nil {
+		return nil, fmt.Errorf("bounding box of %s is not set", b.id)
+	}
+
+	return b.boundingBox, nil
+}
+
+// GetSweptBoundingBox covers the whole move of the last step for CCD bodies, from the start pose
+// to where the body is now. The box at the start is the current one moved back, fast bodies
+// move much more than they turn. Other bodies get their usual bounding box
+func (b *body) GetSweptBoundingBox() (*BoundingBox, error) {
+	bb, err := b.GetBoundingBox()
+	if err != nil || !b.ccd {
+		return bb, err
+	}
+
+	back := b.startPosition.Sub(*b.position)
+	start := BoundingBox{Min: bb.Min.Add(*back), Max: bb.Max.Add(*back)}
+	return &BoundingBox{
+		Min: &vector.Vector3D{X: math.Min(bb.Min.X, start.Min.X), Y: math.Min(bb.Min.Y, start.Min.Y), Z: math.Min(bb.Min.Z, start.Min.Z)},
+		Max: &vector.Vector3D{X: math.Max(bb.Max.X, start.Max.X), Y: math.Max(bb.Max.Y, start.Max.Y), Z: math.Max(bb.Max.Z, start.Max.Z)},
+	}, nil
+}
+
+// AddForce applies the force (world space, at the centre of mass) during the next step
+func (b *body) AddForce(force vector.Vector3D) {
+	b.force = *b.force.Add(force)
+}
+
+// AddTorque applies the torque (world space) during the next step
+func (b *body) AddTorque(torque vector.Vector3D) {
+	b.torque = *b.torque.Add(torque)
+}
+
+// AddImpulse changes the velocity at once, static bodies ignore it
+func (b *body) AddImpulse(impulse vector.Vector3D) {
+	*b.velocity = *b.velocity.Add(*impulse.Mul(b.inverseMass))
+}
+
+// GetForce returns the sum of the forces of the step
+func (b *body) GetForce() vector.Vector3D {
+	return b.force
+}
+
+// GetTorque returns the sum of the torques of the step
+func (b *body) GetTorque() vector.Vector3D {
+	return b.torque
+}
+
+// ClearForces forgets the forces and torques once the integrator used them
+func (b *body) ClearForces() {
+	b.force = vector.Vector3D{}
+	b.torque = vector.Vector3D{}
+}
+
+// SetDamping sets the part of the linear and angular velocity lost per second
+func (b *body) SetDamping(linear, angular float64) {
+	b.linearDamping = linear
+	b.angularDamping = angular
+}
+
+func (b *body) GetDamping() (linear, angular float64) {
+	return b.linearDamping, b.angularDamping
+}
+
+func (b *body) SetCCD(enabled bool) {
+	b.ccd = enabled
+}
+
+func (b *body) GetCCD() bool {
+	return b.ccd
+}
+
+func (b *body) GetId() string {
+	return b.id
+}
+
+func (b *body) SetPosition(position vector.Vector3D) {
+	b.position = &position
+}
+
+func (b *body) GetPosition() (*vector.Vector3D, error) {
+	if b.position == nil {
+		return nil, fmt.Errorf("position of %s is not set", b.id)
+	}
+
+	return b.position, nil
+}
+
+func (b *body) ApplyVelocity(velocity vector.Vector3D) error {
+	if b.velocity == nil {
+		return fmt.Errorf("velocity of %s is not set", b.id)
+	}
+
+	*b.velocity = velocity
+	return nil
+}
+
+func (b *body) GetVelocity() (*vector.Vector3D, error) {
+	if b.velocity == nil {
+		return nil, fmt.Errorf("velocity of %s is not set", b.id)
+	}
+
+	return b.velocity, nil
+}
+
+// SetOrientation normalizes the orientation, so the error of composed turns doesn't grow
+func (b *body) SetOrientation(orientation vector.Quaternion) {
+	b.orientation = orientation.Normalize()
+	b.orientationMatrix = b.orientation.ToMatrix()
+}
+
+func (b *body) GetQuaternion() vector.Quaternion {
+	return *b.orientation
+}
+
+// GetAngle returns the orientation as Euler angles in degrees (X first, then Y, then Z) for the visualizer
+func (b *body) GetAngle() (*vector.Angle3D, error) {
+	if b.orientationMatrix == nil {
+		return nil, fmt.Errorf("orientation of %s is not set", b.id)
+	}
+
+	return b.orientationMatrix.ToAngle(), nil
+}
+
+func (b *body) ApplyRotation(rotation vector.Angle3D) error {
+	if b.rotation == nil {
+		return fmt.Errorf("rotation of %s is not set", b.id)
+	}
+
+	b.rotation = b.rotation.Add(rotation)
+	return nil
+}
+
+func (b *body) GetRotation() (*vector.Angle3D, error) {
+	if b.rotation == nil {
+		return nil, fmt.Errorf("rotation of %s is not set", b.id)
+	}
+
+	return b.rotation, nil
+}
+
+// GetOrientation returns the rotation matrix, its columns are the local axes in world space
+func (b *body) GetOrientation() vector.Matrix3D {
+	return *b.orientationMatrix
+}
+
+func (b *body) SetMass(mass float64) {
+	if mass <= 0 || math.IsInf(mass, 1) {
+		b.mass = 0
+		b.inverseMass = 0
+		return
+	}
+
+	b.mass = mass
+	b.inverseMass = 1 / mass
+}
+
+// GetMass returns 0 for static bodies
+func (b *body) GetMass() float64 {
+	return b.mass
+}
+
+func (b *body) GetInverseMass() float64 {
+	return b.inverseMass
+}
+
+// GetInertia is zero for static bodies, use GetInverseInertia for them
+func (b *body) GetInertia() vector.Matrix3D {
+	return *vector.DiagonalMatrix(*b.unitInertia.Mul(b.mass))
+}
+
+// GetWorldInverseInertia returns the inverse inertia tensor rotated to world space
+func (b *body) GetWorldInverseInertia() vector.Matrix3D {
+	inverse := b.GetInverseInertia()
+	return *b.orientationMatrix.Mul(*inverse.Mul(*b.orientationMatrix.Transpose()))
+}
+
+func (b *body) GetInverseInertia() vector.Matrix3D {
+	if b.inverseMass == 0 {
+		return vector.Matrix3D{}
+	}
+
+	inverse := vector.Vector3D{}
+	if b.unitInertia.X > 0 {
+		inverse.X = b.inverseMass / b.unitInertia.X
+	}
+	if b.unitInertia.Y > 0 {
+		inverse.Y = b.inverseMass / b.unitInertia.Y
+	}
+	if b.unitInertia.Z > 0 {
+		inverse.Z = b.inverseMass / b.unitInertia.Z
+	}
+	return *vector.DiagonalMatrix(inverse)
+}
+
+// SetMaterial also resets the mass to density * volume, SetMass can override it afterwards
+func (b *body) SetMaterial(material Material) {
+	b.material = material
+	b.SetMass(material.Density * b.volume)
+}
+
+func (b *body) GetMaterial() Material {
+	return b.material
+}
@@ -0,0 +1,72 @@
+package objects
+
+import (
+	"BachelorThesis/engine/vector"
+	"math"
+)
+
+// Box is an oriented box, halfExtents are given along its local axes
+type Box struct {
+	body
+
+	halfExtents *vector.Vector3D
+}
+
+func (b *Box) Update(dt float64) {
+	b.move(dt)
+
+	b.boundingBox = b.computeBoundingBox()
+}
+
+// box bounding box, the extents of the rotated box projected on the world axes
+func (b *Box) computeBoundingBox() *BoundingBox {
+	m := b.orientationMatrix
+	h := b.halfExtents
+
+	extents := vector.Vector3D{
+		X: math.Abs(m[0][0])*h.X + math.Abs(m[0][1])*h.Y + math.Abs(m[0][2])*h.Z,
+		Y: math.Abs(m[1][0])*h.X + math.Abs(m[1][1])*h.Y + math.Abs(m[1][2])*h.Z,
+		Z: math.Abs(m[2][0])*h.X + math.Abs(m[2][1])*h.Y + math.Abs(m[2][2])*h.Z,
+	}
+
+	return &BoundingBox{
+		Min: b.position.Sub(extents),
+		Max: b.position.Add(extents),
+	}
+}
+
+func (b *Box) Support(direction vector.Vector3D) *vector.Vector3D {
+	local := b.orientationMatrix.Transpose().MulVector(direction)
+
+	corner := vector.Vector3D{
+		X: math.Copysign(b.halfExtents.X, local.X),
+		Y: math.Copysign(b.halfExtents.Y, local.Y),
+		Z: math.Copysign(b.halfExtents.Z, local.Z),
+	}
+
+	return b.position.Add(*b.orientationMatrix.MulVector(corner))
+}
+
+// Standart object behavior
+
+func NewBox(halfExtents vector.Vector3D, id string) Box {
+	// solid box: I = m/12 (b^2 + c^2) for the full sizes b and c
+	size := halfExtents.Mul(2)
+	inertia := vector.Vector3D{
+		X: (size.Y*size.Y + size.Z*size.Z) / 12,
+		Y: (size.X*size.X + size.Z*size.Z) / 12,
+		Z: (size.X*size.X + size.Y*size.Y) / 12,
+	}
+
+	box := Box{
+		body:        newBody(id, size.X*size.Y*size.Z, inertia),
+		halfExtents: &halfExtents,
+	}
+	box.boundingBox = box.computeBoundingBox()
+
+	return box
+}
+
+func (b *Box) GetHalfExtents() vector.Vector3D {
+	return *b.halfExtents
+}
@@ -0,0 +1,89 @@
+package objects
+
+import (
+	"BachelorThesis/engine/vector"
+	"math"
+)
+
+// Capsule is a segment of length 2*halfHeight along the local Y axis, inflated by radius
+type Capsule struct {
+	body
+
+	radius     float64
+	halfHeight float64
+}
+
+func (c *Capsule) Update(dt float64) {
+	c.move(dt)
+
+	c.boundingBox = c.computeBoundingBox()
+}
+
+// capsule bounding box, the box of the segment grown by the radius
+func (c *Capsule) computeBoundingBox() *BoundingBox {
+	top, bottom := c.GetSegment()
+
+	return &BoundingBox{
+		Min: vector.Vector3D{X: math.Min(top.X, bottom.X), Y: math.Min(top.Y, bottom.Y), Z: math.Min(top.Z, bottom.Z)}.AddFloat(-c.radius),
+		Max: vector.Vector3D{X: math.Max(top.X, bottom.X), Y: math.Max(top.Y, bottom.Y), Z: math.Max(top.Z, bottom.Z)}.AddFloat(c.radius),
+	}
+}
+
+func (c *Capsule) Support(direction vector.Vector3D) *vector.Vector3D {
+	top, bottom := c.GetSegment()
+
+	end := top
+	if direction.Dot(*top.Sub(bottom)) < 0 {
+		end = bottom
+	}
+
+	return end.Add(*direction.Normalize().Mul(c.radius))
+}
+
+// Standart object behavior
+
+func NewCapsule(radius, halfHeight float64, id string) Capsule {
+	capsule := Capsule{
+		body:       newBody(id, capsuleVolume(radius, halfHeight), capsuleInertia(radius, halfHeight)),
+		radius:     radius,
+		halfHeight: halfHeight,
+	}
+	capsule.boundingBox = capsule.computeBoundingBox()
+
+	return capsule
+}
+
+func (c *Capsule) GetRadius() float64 {
+	return c.radius
+}
+
+func (c *Capsule) GetHalfHeight() float64 {
+	return c.halfHeight
+}
+
+// GetSegment returns the end points of the inner segment in world space
+func (c *Capsule) GetSegment() (vector.Vector3D, vector.Vector3D) {
+	axis := c.orientationMatrix.Column(1).Mul(c.halfHeight)
+
+	return *c.position.Add(*axis), *c.position.Sub(*axis)
+}
+
+func capsuleVolume(radius, halfHeight float64) float64 {
+	return math.Pi*radius*radius*2*halfHeight + 4.0/3.0*math.Pi*radius*radius*radius
+}
+
+// capsule inertia for the unit mass: a cylinder plus two hemispheres shifted to its ends
+func capsuleInertia(radius, halfHeight float64) vector.Vector3D {
+	height := 2 * halfHeight
+	r2 := radius * radius
+
+	cylinderVolume := math.Pi * r2 * height
+	cylinderPart := cylinderVolume / capsuleVolume(radius, halfHeight)
+	spheresPart := 1 - cylinderPart
+
+	along := cylinderPart*r2/2 + spheresPart*2*r2/5
+	side := cylinderPart*(height*height/12+r2/4) +
+		spheresPart*(2*r2/5+height*height/4+3*height*radius/8)
+
+	return vector.Vector3D{X: side, Y: along, Z: side}
+}
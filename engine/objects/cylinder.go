@@ -0,0 +1,71 @@
+package objects
+
+import (
+	"BachelorThesis/engine/vector"
+	"math"
+)
+
+// Cylinder has its axis along the local Y axis, the caps are 2*halfHeight apart
+type Cylinder struct {
+	body
+
+	radius     float64
+	halfHeight float64
+}
+
+func (c *Cylinder) Update(dt float64) {
+	c.move(dt)
+
+	c.boundingBox = c.computeBoundingBox()
+}
+
+// cylinder bounding box, along every world axis the caps add radius*sin of the angle to the cylinder axis
+func (c *Cylinder) computeBoundingBox() *BoundingBox {
+	axis := c.orientationMatrix.Column(1)
+
+	extents := vector.Vector3D{
+		X: c.halfHeight*math.Abs(axis.X) + c.radius*math.Sqrt(math.Max(0, 1-axis.X*axis.X)),
+		Y: c.halfHeight*math.Abs(axis.Y) + c.radius*math.Sqrt(math.Max(0, 1-axis.Y*axis.Y)),
+		Z: c.halfHeight*math.Abs(axis.Z) + c.radius*math.Sqrt(math.Max(0, 1-axis.Z*axis.Z)),
+	}
+
+	return &BoundingBox{
+		Min: c.position.Sub(extents),
+		Max: c.position.Add(extents),
+	}
+}
+
+func (c *Cylinder) Support(direction vector.Vector3D) *vector.Vector3D {
+	axis := c.orientationMatrix.Column(1)
+
+	along := direction.Dot(*axis)
+	radial := direction.Sub(*axis.Mul(along)).Normalize()
+
+	return c.position.Add(*axis.Mul(math.Copysign(c.halfHeight, along))).Add(*radial.Mul(c.radius))
+}
+
+// Standart object behavior
+
+func NewCylinder(radius, halfHeight float64, id string) Cylinder {
+	// solid cylinder along Y: Iy = 1/2 m r^2, Ix = Iz = m/12 (3r^2 + h^2)
+	height := 2 * halfHeight
+	side := (3*radius*radius + height*height) / 12
+	inertia := vector.Vector3D{X: side, Y: radius * radius / 2, Z: side}
+
+	cylinder := Cylinder{
+		body:       newBody(id, math.Pi*radius*radius*height, inertia),
+		radius:     radius,
+		halfHeight: halfHeight,
+	}
+	cylinder.boundingBox = cylinder.computeBoundingBox()
+
+	return cylinder
+}
+
+func (c *Cylinder) GetRadius() float64 {
+	return c.radius
+}
+
+func (c *Cylinder) GetHalfHeight() float64 {
+	return c.halfHeight
+}
@@ -0,0 +1,136 @@
+package objects
+
+import (
+	"log"
+	"math"
+)
+
+// CombineRule tells how the properties of two materials are mixed for a contact
+type CombineRule string
+
+// rules in the order of priority, if two materials use different rules the later one wins
+const (
+	AverageCombine  CombineRule = "Average"
+	MinCombine      CombineRule = "Min"
+	MultiplyCombine CombineRule = "Multiply"
+	MaxCombine      CombineRule = "Max"
+)
+
+var combinePriority = map[CombineRule]int{
+	AverageCombine:  0,
+	MinCombine:      1,
+	MultiplyCombine: 2,
+	MaxCombine:      3,
+}
+
+type Material struct {
+	Name string
+
+	// 0 is a perfectly inelastic collision, 1 is a perfectly elastic one
+	Restitution float64
+
+	// Coulomb friction: static holds resting contacts, dynamic slows down sliding ones
+	StaticFriction  float64
+	DynamicFriction float64
+
+	// mass = density * volume
+	Density float64
+
+	RestitutionCombine CombineRule
+	FrictionCombine    CombineRule
+}
+
+var (
+	DefaultMaterial = Material{
+		Name:               "Default",
+		Restitution:        0.5,
+		StaticFriction:     0.6,
+		DynamicFriction:    0.4,
+		Density:            1.0,
+		RestitutionCombine: AverageCombine,
+		FrictionCombine:    AverageCombine,
+	}
+
+	RubberMaterial = Material{
+		Name:               "Rubber",
+		Restitution:        0.85,
+		StaticFriction:     1.0,
+		DynamicFriction:    0.8,
+		Density:            1.1,
+		RestitutionCombine: MaxCombine,
+		FrictionCombine:    AverageCombine,
+	}
+
+	SteelMaterial = Material{
+		Name:               "Steel",
+		Restitution:        0.3,
+		StaticFriction:     0.5,
+		DynamicFriction:    0.35,
+		Density:            7.8,
+		RestitutionCombine: AverageCombine,
+		FrictionCombine:    AverageCombine,
+	}
+
+	WoodMaterial = Material{
+		Name:               "Wood",
+		Restitution:        0.4,
+		StaticFriction:     0.5,
+		DynamicFriction:    0.3,
+		Density:            0.6,
+		RestitutionCombine: AverageCombine,
+		FrictionCombine:    AverageCombine,
+	}
+
+	IceMaterial = Material{
+		Name:               "Ice",
+		Restitution:        0.1,
+		StaticFriction:     0.05,
+		DynamicFriction:    0.02,
+		Density:            0.9,
+		RestitutionCombine: AverageCombine,
+		FrictionCombine:    MinCombine,
+	}
+
+	Materials = []Material{DefaultMaterial, RubberMaterial, SteelMaterial, WoodMaterial, IceMaterial}
+)
+
+// PairMaterial is what a resolver uses for a contact of two bodies
+type PairMaterial struct {
+	Restitution     float64
+	StaticFriction  float64
+	DynamicFriction float64
+}
+
+func CombineMaterials(a, b Material) PairMaterial {
+	frictionRule := strongerRule(a.FrictionCombine, b.FrictionCombine)
+
+	return PairMaterial{
+		Restitution:     combine(a.Restitution, b.Restitution, strongerRule(a.RestitutionCombine, b.RestitutionCombine)),
+		StaticFriction:  combine(a.StaticFriction, b.StaticFriction, frictionRule),
+		DynamicFriction: combine(a.DynamicFriction, b.DynamicFriction, frictionRule),
+	}
+}
+
+func strongerRule(a, b CombineRule) CombineRule {
+	if combinePriority[b] > combinePriority[a] {
+		return b
+	}
+	return a
+}
+
+func combine(a, b float64, rule CombineRule) float64 {
+	switch rule {
+	case AverageCombine, "":
+		return (a + b) / 2
+	case MinCombine:
+		return math.Min(a, b)
+	case MultiplyCombine:
+		return a * b
+	case MaxCombine:
+		return math.Max(a, b)
+	default:
+		log.Panicf("Unknown combine rule: %s", rule)
+	}
+
+	return 0
+}
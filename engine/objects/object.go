@@ -10,8 +10,14 @@ type BoundingBox struct {
 }
 
 type Object interface {
-	Update()
+	// Update moves the body by its velocities for dt seconds and refreshes the bounding box
+	Update(dt float64)
 	GetBoundingBox() (*BoundingBox, error)
+	// the bounding box of the whole frame move for CCD bodies, see SetCCD
+	GetSweptBoundingBox() (*BoundingBox, error)
+
+	// Support returns the farthest point of the shape in the given direction (world space)
+	Support(direction vector.Vector3D) *vector.Vector3D
 
 	SetPosition(vector.Vector3D)
 	GetPosition() (*vector.Vector3D, error)
@@ -19,12 +25,53 @@ type Object interface {
 	ApplyVelocity(vector.Vector3D) error
 	GetVelocity() (*vector.Vector3D, error)
 
-	SetAngle(vector.Angle3D)
+	// orientation is a unit quaternion, Euler angles are only for the visualizer
+	SetOrientation(vector.Quaternion)
+	GetQuaternion() vector.Quaternion
 	GetAngle() (*vector.Angle3D, error)
 
+	// the pose before the integrator moved the body this step, the substepping solver replays the step from it.
+	// The CCD rewind and the solvers change the pose, not the saved one
+	SaveStartPose()
+	GetStartPose() (vector.Vector3D, vector.Quaternion)
+
+	// rotation is the angular velocity in degrees per second around the world axes
 	ApplyRotation(vector.Angle3D) error
 	GetRotation() (*vector.Angle3D, error)
 
+	// forces and torques (world space) act during the next step, an impulse changes the velocity at once
+	AddForce(vector.Vector3D)
+	AddTorque(vector.Vector3D)
+	AddImpulse(vector.Vector3D)
+	// the sums of the forces and torques of the step, the integrator clears them after the step
+	GetForce() vector.Vector3D
+	GetTorque() vector.Vector3D
+	ClearForces()
+	// the part of the linear and angular velocity lost per second
+	SetDamping(linear, angular float64)
+	GetDamping() (linear, angular float64)
+
+	// columns are the local axes in world space
+	GetOrientation() vector.Matrix3D
+
+	// mass 0 means infinite mass, such a body is static
+	SetMass(float64)
+	GetMass() float64
+	GetInverseMass() float64
+
+	// inertia tensor in body space, zero inverse for static bodies
+	GetInertia() vector.Matrix3D
+	GetInverseInertia() vector.Matrix3D
+	GetWorldInverseInertia() vector.Matrix3D
+
+	// restitution, friction and density, setting it recomputes the mass
+	SetMaterial(Material)
+	GetMaterial() Material
+
+	// CCD bodies are tested along their move, so they don't pass through thin objects
+	SetCCD(bool)
+	GetCCD() bool
+
 	GetId() string
 }
 
@@ -0,0 +1,120 @@
+// Package objectstest builds the scenes the tests and benchmarks of the collision stages share
+package objectstest
+
+import (
+	"BachelorThesis/engine/objects"
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// the demo scene: 1024 unit spheres in a cube of 50, the random scenes keep its density
+const (
+	DENSITY_OBJECTS = 1024
+	DENSITY_SIZE    = 50.0
+)
+
+// Sizes are the object counts of the benchmarks, 1k to 64k
+var Sizes = []int{1 << 10, 1 << 12, 1 << 14, 1 << 16}
+
+// SizeName names a size for b.Run, 1024 is "1k"
+func SizeName(n int) string {
+	if n%1024 == 0 {
+		return fmt.Sprintf("%dk", n/1024)
+	}
+	return fmt.Sprint(n)
+}
+
+// Sphere returns a sphere whose bounding box and start pose are already where it is
+func Sphere(id string, position vector.Vector3D, radius float64) *objects.Sphere {
+	sphere := objects.NewSphere(radius, id)
+	sphere.SetPosition(position)
+	sphere.Update(0)
+	sphere.SaveStartPose()
+	return &sphere
+}
+
+// Box returns an axis aligned box whose bounding box and start pose are already where it is
+func Box(id string, position, halfExtents vector.Vector3D) *objects.Box {
+	box := objects.NewBox(halfExtents, id)
+	box.SetPosition(position)
+	box.Update(0)
+	box.SaveStartPose()
+	return &box
+}
+
+// Capsule returns a capsule along Y whose bounding box and start pose are already where it is
+func Capsule(id string, position vector.Vector3D, radius, halfHeight float64) *objects.Capsule {
+	capsule := objects.NewCapsule(radius, halfHeight, id)
+	capsule.SetPosition(position)
+	capsule.Update(0)
+	capsule.SaveStartPose()
+	return &capsule
+}
+
+// RandomSpheres scatters n unit spheres with random velocities in a cube that grows
+// with n, so every sphere has as many neighbours as in the demo scene
+func RandomSpheres(n int, seed int64) []objects.Object {
+	random := rand.New(rand.NewSource(seed))
+	size := DENSITY_SIZE * math.Cbrt(float64(n)/DENSITY_OBJECTS)
+	coordinate := func() float64 {
+		return (random.Float64() - 0.5) * size
+	}
+	speed := func() float64 {
+		return (random.Float64()*2 - 1) * 6
+	}
+
+	pool := make([]objects.Object, n)
+	for i := range pool {
+		sphere := Sphere(fmt.Sprintf("sphere_%d", i), vector.Vector3D{X: coordinate(), Y: coordinate(), Z: coordinate()}, 1)
+		sphere.ApplyVelocity(vector.Vector3D{X: speed(), Y: speed(), Z: speed()})
+		pool[i] = sphere
+	}
+	return pool
+}
+
+// TouchingPairs returns the pairs of spheres of the pool that touch, a < b
+func TouchingPairs(pool []objects.Object) [][2]int {
+	// a grid of cells as large as the largest sphere, touching spheres are in neighbouring cells
+	cell := 0.0
+	for _, obj := range pool {
+		cell = math.Max(cell, 2*obj.(*objects.Sphere).GetRadius())
+	}
+
+	type key [3]int
+	grid := make(map[key][]int)
+	keyOf := func(i int) key {
+		position, _ := pool[i].GetPosition()
+		return key{int(math.Floor(position.X / cell)), int(math.Floor(position.Y / cell)), int(math.Floor(position.Z / cell))}
+	}
+	for i := range pool {
+		k := keyOf(i)
+		grid[k] = append(grid[k], i)
+	}
+
+	pairs := make([][2]int, 0)
+	for a := range pool {
+		k := keyOf(a)
+		posA, _ := pool[a].GetPosition()
+		radiusA := pool[a].(*objects.Sphere).GetRadius()
+
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dz := -1; dz <= 1; dz++ {
+					for _, b := range grid[key{k[0] + dx, k[1] + dy, k[2] + dz}] {
+						if b <= a {
+							continue
+						}
+						posB, _ := pool[b].GetPosition()
+						radii := radiusA + pool[b].(*objects.Sphere).GetRadius()
+						if posA.Sub(*posB).LengthSq() <= radii*radii {
+							pairs = append(pairs, [2]int{a, b})
+						}
+					}
+				}
+			}
+		}
+	}
+	return pairs
+}
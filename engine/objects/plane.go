@@ -0,0 +1,105 @@
+package objects
+
+import (
+	"BachelorThesis/engine/vector"
+	"fmt"
+	"math"
+)
+
+// Plane is a static half-space, everything behind the plane (against the normal) is solid.
+// It never moves and is not a part of the broad phase, see collision.ProcessCollisions
+type Plane struct {
+	body
+
+	normal *vector.Vector3D
+}
+
+// static body, nothing to update
+func (p *Plane) Update(float64) {}
+
+// plane bounding box is infinite, unless the normal is along a world axis
+func (p *Plane) computeBoundingBox() *BoundingBox {
+	inf := math.Inf(1)
+	bb := &BoundingBox{
+		Min: &vector.Vector3D{X: -inf, Y: -inf, Z: -inf},
+		Max: &vector.Vector3D{X: inf, Y: inf, Z: inf},
+	}
+
+	switch *p.normal {
+	case vector.Vector3D{X: 1}:
+		bb.Max.X = p.position.X
+	case vector.Vector3D{X: -1}:
+		bb.Min.X = p.position.X
+	case vector.Vector3D{Y: 1}:
+		bb.Max.Y = p.position.Y
+	case vector.Vector3D{Y: -1}:
+		bb.Min.Y = p.position.Y
+	case vector.Vector3D{Z: 1}:
+		bb.Max.Z = p.position.Z
+	case vector.Vector3D{Z: -1}:
+		bb.Min.Z = p.position.Z
+	}
+
+	return bb
+}
+
+// a half-space has no finite support point, so planes are only tested with
+// dedicated routines and this just returns the point the plane goes through
+func (p *Plane) Support(direction vector.Vector3D) *vector.Vector3D {
+	point := *p.position
+	return &point
+}
+
+// Standart object behavior
+
+func NewPlane(normal, point vector.Vector3D, id string) Plane {
+	// planes are static, so their mass is infinite
+	plane := Plane{
+		body:   newBody(id, 0, vector.Vector3D{}),
+		normal: normal.Normalize(),
+	}
+	plane.position = &point
+	plane.boundingBox = plane.computeBoundingBox()
+
+	return plane
+}
+
+// NewWorldBox returns six planes facing inside the box from min to max,
+// so objects can not leave it
+func NewWorldBox(min, max vector.Vector3D, id string) []Plane {
+	return []Plane{
+		NewPlane(vector.Vector3D{X: 1}, min, fmt.Sprintf("%s_left", id)),
+		NewPlane(vector.Vector3D{X: -1}, max, fmt.Sprintf("%s_right", id)),
+		NewPlane(vector.Vector3D{Y: 1}, min, fmt.Sprintf("%s_floor", id)),
+		NewPlane(vector.Vector3D{Y: -1}, max, fmt.Sprintf("%s_ceiling", id)),
+		NewPlane(vector.Vector3D{Z: 1}, min, fmt.Sprintf("%s_back", id)),
+		NewPlane(vector.Vector3D{Z: -1}, max, fmt.Sprintf("%s_front", id)),
+	}
+}
+
+func (p *Plane) SetPosition(position vector.Vector3D) {
+	p.position = &position
+	p.boundingBox = p.computeBoundingBox()
+}
+
+func (p *Plane) ApplyVelocity(velocity vector.Vector3D) error {
+	return fmt.Errorf("%s is static and can not move", p.id)
+}
+
+// planes stay static whatever mass is given
+func (p *Plane) SetMass(mass float64) {
+	p.body.SetMass(0)
+}
+
+func (p *Plane) ApplyRotation(rotation vector.Angle3D) error {
+	return fmt.Errorf("%s is static and can not rotate", p.id)
+}
+
+func (p *Plane) GetNormal() vector.Vector3D {
+	return *p.normal
+}
+
+// GetOffset returns d of the plane equation normal*x = d
+func (p *Plane) GetOffset() float64 {
+	return p.normal.Dot(*p.position)
+}
@@ -2,135 +2,46 @@ package objects
 
 import (
 	"BachelorThesis/engine/vector"
-	"fmt"
+	"math"
 )
 
 type Sphere struct {
-	radius float64
-	id     string
-
-	position *vector.Vector3D
-	velocity *vector.Vector3D
-
-	angle    *vector.Angle3D
-	rotation *vector.Angle3D
+	body
 
-	boundingBox *BoundingBox
+	radius float64
 }
 
-func (s *Sphere) Update() {
-	s.SetPosition(*s.position.Add(*s.velocity))
-	s.SetAngle(*s.angle.Add(*s.rotation))
+func (s *Sphere) Update(dt float64) {
+	s.move(dt)
 
 	// sphere bounding box
 	s.boundingBox = &BoundingBox{
 		Min: s.position.AddFloat(-s.radius),
 		Max: s.position.AddFloat(s.radius),
 	}
-
-	/*
-		// temp
-		if s.position.X > 10 || s.position.X < -10 {
-			s.velocity.X = -s.velocity.X
-		}
-		if s.position.Y > 10 || s.position.Y < -10 {
-			s.velocity.Y = -s.velocity.Y
-		}
-		if s.position.Z > 10 || s.position.Z < -10 {
-			s.velocity.Z = -s.velocity.Z
-		}
-	*/
 }
 
-func (s *Sphere) GetBoundingBox() (*BoundingBox, error) {
-	if s.boundingBox == nil {
-		return nil, fmt.Errorf("bounding box of %s is not set", s.id)
-	}
-
-	return s.boundingBox, nil
+func (s *Sphere) Support(direction vector.Vector3D) *vector.Vector3D {
+	return s.position.Add(*direction.Normalize().Mul(s.radius))
 }
 
 // Standart object behavior
 
 func NewSphere(radius float64, id string) Sphere {
-	return Sphere{
-		id:     id,
-		radius: radius,
-
-		position: vector.ZeroVector(),
-		velocity: vector.ZeroVector(),
-
-		angle:    vector.ZeroAngle(),
-		rotation: vector.ZeroAngle(),
-
-		boundingBox: &BoundingBox{
-			Min: vector.ZeroVector().AddFloat(-radius),
-			Max: vector.ZeroVector().AddFloat(radius),
-		},
-	}
-}
-
-func (s *Sphere) GetId() string {
-	return s.id
-}
-
-func (s *Sphere) SetPosition(position vector.Vector3D) {
-	s.position = &position
-}
-
-func (s *Sphere) GetPosition() (*vector.Vector3D, error) {
-	if s.position == nil {
-		return nil, fmt.Errorf("position of %s is not set", s.id)
-	}
+	// solid sphere: I = 2/5 m r^2
+	moment := 2.0 / 5.0 * radius * radius
 
-	return s.position, nil
-}
-
-func (s *Sphere) ApplyVelocity(velocity vector.Vector3D) error {
-	if s.velocity == nil {
-		return fmt.Errorf("velocity of %s is not set", s.id)
-	}
-
-	*s.velocity = velocity
-	return nil
-}
-
-func (s *Sphere) GetVelocity() (*vector.Vector3D, error) {
-	if s.velocity == nil {
-		return nil, fmt.Errorf("velocity of %s is not set", s.id)
-	}
-
-	return s.velocity, nil
-}
-
-func (s *Sphere) SetAngle(angle vector.Angle3D) {
-	s.angle = &angle
-	s.angle.Normalize()
-}
-
-func (s *Sphere) GetAngle() (*vector.Angle3D, error) {
-	if s.angle == nil {
-		return nil, fmt.Errorf("angle of %s is not set", s.id)
-	}
-
-	return s.angle, nil
-}
-
-func (s *Sphere) ApplyRotation(rotation vector.Angle3D) error {
-	if s.rotation == nil {
-		return fmt.Errorf("rotation of %s is not set", s.id)
+	sphere := Sphere{
+		body:   newBody(id, 4.0/3.0*math.Pi*radius*radius*radius, vector.Vector3D{X: moment, Y: moment, Z: moment}),
+		radius: radius,
 	}
 
-	s.rotation = s.rotation.Add(rotation)
-	return nil
-}
-
-func (s *Sphere) GetRotation() (*vector.Angle3D, error) {
-	if s.rotation == nil {
-		return nil, fmt.Errorf("rotation of %s is not set", s.id)
+	sphere.boundingBox = &BoundingBox{
+		Min: vector.ZeroVector().AddFloat(-radius),
+		Max: vector.ZeroVector().AddFloat(radius),
 	}
 
-	return s.rotation, nil
+	return sphere
 }
 
 func (s *Sphere) GetRadius() float64 {
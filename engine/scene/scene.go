@@ -0,0 +1,61 @@
+package scene
+
+import (
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/objects"
+	st "BachelorThesis/engine/singletone"
+	"BachelorThesis/engine/vector"
+	"math/rand"
+	"time"
+)
+
+const (
+	SPHERE_RADIUS = 1
+	// spheres appear in a cube of that half size around the origin
+	SPAWN_SIZE = 25
+	// m/s along each axis
+	MAX_INITIAL_SPEED = 6.0
+)
+
+// the scene is random, Seed makes it repeatable
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+func Seed(seed int64) {
+	random = rand.New(rand.NewSource(seed))
+}
+
+// AddWorldBox encloses the scene in six planes if constants.WorldBox is set
+func AddWorldBox(engine *st.Engine) {
+	if !constants.WorldBox {
+		return
+	}
+
+	worldMin := vector.Vector3D{X: -constants.WorldSize, Y: -constants.WorldSize, Z: -constants.WorldSize}
+	worldMax := vector.Vector3D{X: constants.WorldSize, Y: constants.WorldSize, Z: constants.WorldSize}
+	for _, plane := range objects.NewWorldBox(worldMin, worldMax, "world") {
+		engine.AddObject(&plane)
+	}
+}
+
+// AddSphere adds a sphere of a random material at a random place with a random velocity
+func AddSphere(engine *st.Engine, id string) *objects.Sphere {
+	position := vector.Vector3D{
+		X: float64(random.Intn(2*SPAWN_SIZE) - SPAWN_SIZE),
+		Y: float64(random.Intn(2*SPAWN_SIZE) - SPAWN_SIZE),
+		Z: float64(random.Intn(2*SPAWN_SIZE) - SPAWN_SIZE),
+	}
+	// [-MAX_INITIAL_SPEED, MAX_INITIAL_SPEED)
+	velocity := vector.Vector3D{
+		X: (random.Float64()*2 - 1) * MAX_INITIAL_SPEED,
+		Y: (random.Float64()*2 - 1) * MAX_INITIAL_SPEED,
+		Z: (random.Float64()*2 - 1) * MAX_INITIAL_SPEED,
+	}
+
+	sphere := objects.NewSphere(SPHERE_RADIUS, id)
+	sphere.SetMaterial(objects.Materials[random.Intn(len(objects.Materials))])
+	sphere.SetPosition(position)
+	sphere.ApplyVelocity(velocity)
+
+	engine.AddObject(&sphere)
+	return &sphere
+}
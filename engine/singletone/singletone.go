@@ -2,8 +2,11 @@ package singletone
 
 import (
 	"BachelorThesis/engine/collision"
+	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/integration"
 	"BachelorThesis/engine/objects"
 	"context"
+	"math"
 	"sync"
 )
 
@@ -12,21 +15,28 @@ type Engine struct {
 	SecondaryAlgorithm string
 	ResolveAlgorithm   string
 
+	Integrator integration.Integrator
+
 	Context        context.Context
 	CollisionStart chan struct{}
 	CollisionEnd   chan struct{}
 
 	mu *sync.Mutex
 
+	// time not simulated yet, the world only moves in steps of constants.TimeStep
+	accumulator float64
+
 	ObjectPool *[]objects.Object
 }
 
-func NewEngine(algorithm, secondaryAlgorithm, resolveAlgorithm string, pool *[]objects.Object, ctx context.Context) *Engine {
+func NewEngine(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator string, pool *[]objects.Object, ctx context.Context) *Engine {
 	return &Engine{
 		Algorithm:          algorithm,
 		SecondaryAlgorithm: secondaryAlgorithm,
 		ResolveAlgorithm:   resolveAlgorithm,
 
+		Integrator: integration.New(integrator),
+
 		Context:        ctx,
 		CollisionStart: make(chan struct{}),
 		CollisionEnd:   make(chan struct{}),
@@ -58,7 +68,14 @@ func (e *Engine) StartEngineLoop() {
 	}
 }
 
+// update moves the world by one fixed step: the integrator moves the bodies under gravity
+// and their forces, then the collisions of the new positions are resolved
 func (e *Engine) update() {
+	acceleration := integration.Forces(constants.Gravity)
+	for _, object := range *e.ObjectPool {
+		e.Integrator.Integrate(object, constants.TimeStep, acceleration)
+	}
+
 	collision.ProcessCollisions(e.ObjectPool, e.Algorithm, e.SecondaryAlgorithm, e.ResolveAlgorithm)
 }
 
@@ -81,3 +98,21 @@ func (e *Engine) ProcessCollisions() {
 
 	<-e.CollisionEnd
 }
+
+// Step advances the world by dt seconds of real time in fixed steps of constants.TimeStep.
+// The rest of dt waits for the next call. If the world falls behind by more than
+// constants.MaxStepsPerFrame steps, the backlog is dropped instead of slowing every next frame down
+func (e *Engine) Step(dt float64) {
+	e.accumulator += dt
+
+	steps := 0
+	for e.accumulator >= constants.TimeStep && steps < constants.MaxStepsPerFrame {
+		e.ProcessCollisions()
+		e.accumulator -= constants.TimeStep
+		steps++
+	}
+
+	if steps == constants.MaxStepsPerFrame {
+		e.accumulator = math.Mod(e.accumulator, constants.TimeStep)
+	}
+}
@@ -1,5 +1,7 @@
 package vector
 
+import "math"
+
 type Angle3D struct {
 	X float64
 	Y float64
@@ -26,19 +28,20 @@ func (a Angle3D) AddFloat(f float64) *Angle3D {
 	}
 }
 
-func (a *Angle3D) Normalize() {
-	xOffset := int(a.X / 360)
-	if xOffset != 0 {
-		a.X = a.X - 360*float64(xOffset)
-	}
-
-	yOffset := int(a.Y / 360)
-	if yOffset != 0 {
-		a.Y = a.Y - 360*float64(yOffset)
+// Radians converts the angle from degrees to a vector in radians
+func (a Angle3D) Radians() *Vector3D {
+	return &Vector3D{
+		X: a.X * math.Pi / 180,
+		Y: a.Y * math.Pi / 180,
+		Z: a.Z * math.Pi / 180,
 	}
+}
 
-	zOffset := int(a.Z / 360)
-	if zOffset != 0 {
-		a.Z = a.Z - 360*float64(zOffset)
+// AngleFromRadians converts a vector in radians to an angle in degrees
+func AngleFromRadians(v Vector3D) *Angle3D {
+	return &Angle3D{
+		X: v.X * 180 / math.Pi,
+		Y: v.Y * 180 / math.Pi,
+		Z: v.Z * 180 / math.Pi,
 	}
 }
@@ -0,0 +1,72 @@
+package vector
+
+import "math"
+
+// Matrix3D is a row-major 3x3 matrix, used for orientations
+type Matrix3D [3][3]float64
+
+func IdentityMatrix() *Matrix3D {
+	return &Matrix3D{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	}
+}
+
+func DiagonalMatrix(d Vector3D) *Matrix3D {
+	return &Matrix3D{
+		{d.X, 0, 0},
+		{0, d.Y, 0},
+		{0, 0, d.Z},
+	}
+}
+
+// ToAngle extracts Euler angles in degrees of the rotation applying X first, then Y, then Z.
+// Orientations are quaternions, the angles are only for the visualizer
+func (m Matrix3D) ToAngle() *Angle3D {
+	sy := math.Max(-1, math.Min(1, -m[2][0]))
+	y := math.Asin(sy)
+
+	var x, z float64
+	if math.Abs(sy) < 1-1e-9 {
+		x = math.Atan2(m[2][1], m[2][2])
+		z = math.Atan2(m[1][0], m[0][0])
+	} else {
+		// gimbal lock, only x + z or x - z is known
+		x = 0
+		z = math.Atan2(-m[0][1], m[1][1])
+	}
+
+	return AngleFromRadians(Vector3D{X: x, Y: y, Z: z})
+}
+
+func (m Matrix3D) Mul(m2 Matrix3D) *Matrix3D {
+	result := Matrix3D{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			result[i][j] = m[i][0]*m2[0][j] + m[i][1]*m2[1][j] + m[i][2]*m2[2][j]
+		}
+	}
+	return &result
+}
+
+func (m Matrix3D) MulVector(v Vector3D) *Vector3D {
+	return &Vector3D{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+func (m Matrix3D) Transpose() *Matrix3D {
+	return &Matrix3D{
+		{m[0][0], m[1][0], m[2][0]},
+		{m[0][1], m[1][1], m[2][1]},
+		{m[0][2], m[1][2], m[2][2]},
+	}
+}
+
+// Column returns the i-th column, for an orientation it is the i-th local axis in world space
+func (m Matrix3D) Column(i int) *Vector3D {
+	return &Vector3D{X: m[0][i], Y: m[1][i], Z: m[2][i]}
+}
@@ -0,0 +1,130 @@
+package vector
+
+import "math"
+
+// Quaternion is W + X i + Y j + Z k, unit quaternions are orientations.
+// Unlike Euler angles they compose without gimbal lock
+type Quaternion struct {
+	W float64
+	X float64
+	Y float64
+	Z float64
+}
+
+func IdentityQuaternion() *Quaternion {
+	return &Quaternion{W: 1}
+}
+
+// AxisAngleQuaternion is the rotation by angle (radians) around the unit axis
+func AxisAngleQuaternion(axis Vector3D, angle float64) *Quaternion {
+	s, c := math.Sincos(angle / 2)
+	return &Quaternion{W: c, X: axis.X * s, Y: axis.Y * s, Z: axis.Z * s}
+}
+
+// RotationVectorQuaternion is the rotation by |v| radians around v, angular velocity times
+// the time step gives the turn of the step
+func RotationVectorQuaternion(v Vector3D) *Quaternion {
+	angle := v.Length()
+	if angle == 0 {
+		return IdentityQuaternion()
+	}
+	return AxisAngleQuaternion(*v.Mul(1 / angle), angle)
+}
+
+// Mul composes the rotations, q2 is applied first
+func (q Quaternion) Mul(q2 Quaternion) *Quaternion {
+	return &Quaternion{
+		W: q.W*q2.W - q.X*q2.X - q.Y*q2.Y - q.Z*q2.Z,
+		X: q.W*q2.X + q.X*q2.W + q.Y*q2.Z - q.Z*q2.Y,
+		Y: q.W*q2.Y - q.X*q2.Z + q.Y*q2.W + q.Z*q2.X,
+		Z: q.W*q2.Z + q.X*q2.Y - q.Y*q2.X + q.Z*q2.W,
+	}
+}
+
+func (q Quaternion) Dot(q2 Quaternion) float64 {
+	return q.W*q2.W + q.X*q2.X + q.Y*q2.Y + q.Z*q2.Z
+}
+
+func (q Quaternion) Length() float64 {
+	return math.Sqrt(q.Dot(q))
+}
+
+// Normalize returns the unit quaternion, the identity for the zero one.
+// Products of unit quaternions slowly lose the unit length, so orientations are normalized after every turn
+func (q Quaternion) Normalize() *Quaternion {
+	length := q.Length()
+	if length == 0 {
+		return IdentityQuaternion()
+	}
+	return &Quaternion{W: q.W / length, X: q.X / length, Y: q.Y / length, Z: q.Z / length}
+}
+
+// Conjugate is the inverse rotation of a unit quaternion
+func (q Quaternion) Conjugate() *Quaternion {
+	return &Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Rotate turns the vector by the rotation
+func (q Quaternion) Rotate(v Vector3D) *Vector3D {
+	u := Vector3D{X: q.X, Y: q.Y, Z: q.Z}
+	// v + 2w (u x v) + 2 u x (u x v)
+	t := u.Cross(v).Mul(2)
+	return v.Add(*t.Mul(q.W)).Add(*u.Cross(*t))
+}
+
+// ToAxisAngle returns the unit axis and the angle (radians, 0..pi) of the rotation,
+// any axis fits the identity
+func (q Quaternion) ToAxisAngle() (Vector3D, float64) {
+	if q.W < 0 {
+		q = Quaternion{W: -q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+	}
+
+	s := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if s == 0 {
+		return Vector3D{X: 1}, 0
+	}
+	return Vector3D{X: q.X / s, Y: q.Y / s, Z: q.Z / s}, 2 * math.Atan2(s, q.W)
+}
+
+// ToRotationVector is the inverse of RotationVectorQuaternion: the axis scaled by the angle (radians, 0..pi)
+func (q Quaternion) ToRotationVector() *Vector3D {
+	axis, angle := q.ToAxisAngle()
+	return axis.Mul(angle)
+}
+
+// Slerp turns from q to q2 at a constant angular speed, t = 0 gives q and t = 1 gives q2.
+// It takes the shorter way, q2 and -q2 are the same orientation
+func (q Quaternion) Slerp(q2 Quaternion, t float64) *Quaternion {
+	cos := q.Dot(q2)
+	if cos < 0 {
+		q2 = Quaternion{W: -q2.W, X: -q2.X, Y: -q2.Y, Z: -q2.Z}
+		cos = -cos
+	}
+
+	// close orientations, the linear interpolation is as good and doesn't divide by a tiny sine
+	wa, wb := 1-t, t
+	if cos < 1-1e-6 {
+		angle := math.Acos(cos)
+		sin := math.Sin(angle)
+		wa = math.Sin((1-t)*angle) / sin
+		wb = math.Sin(t*angle) / sin
+	}
+
+	return Quaternion{
+		W: wa*q.W + wb*q2.W,
+		X: wa*q.X + wb*q2.X,
+		Y: wa*q.Y + wb*q2.Y,
+		Z: wa*q.Z + wb*q2.Z,
+	}.Normalize()
+}
+
+// ToMatrix returns the rotation matrix of a unit quaternion
+func (q Quaternion) ToMatrix() *Matrix3D {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+
+	return &Matrix3D{
+		{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y)},
+		{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x)},
+		{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y)},
+	}
+}
@@ -67,3 +67,15 @@ func (v Vector3D) Mul(f float64) *Vector3D {
 func (v Vector3D) Dot(v2 Vector3D) float64 {
 	return v.X*v2.X + v.Y*v2.Y + v.Z*v2.Z
 }
+
+func (v Vector3D) Cross(v2 Vector3D) *Vector3D {
+	return &Vector3D{
+		X: v.Y*v2.Z - v.Z*v2.Y,
+		Y: v.Z*v2.X - v.X*v2.Z,
+		Z: v.X*v2.Y - v.Y*v2.X,
+	}
+}
+
+func (v Vector3D) Negate() *Vector3D {
+	return &Vector3D{-v.X, -v.Y, -v.Z}
+}
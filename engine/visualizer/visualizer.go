@@ -2,7 +2,9 @@ package visualizer
 
 import (
 	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/integration"
 	"BachelorThesis/engine/objects"
+	sc "BachelorThesis/engine/scene"
 	st "BachelorThesis/engine/singletone"
 	"BachelorThesis/engine/vector"
 	"context"
@@ -16,8 +18,7 @@ import (
 )
 
 const (
-	title        = "Bachelor Thesis Visualization"
-	sphereRadius = 1
+	title = "Bachelor Thesis Visualization"
 )
 
 type obj struct {
@@ -25,7 +26,9 @@ type obj struct {
 	object    objects.Object
 }
 
-func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
+// Start shows the simulation until the window is closed or the context of the engine is done,
+// it starts with the given number of spheres and doubles them every minute
+func Start(engineSingletone *st.Engine, spheres int, cancel context.CancelFunc) {
 	// scene setup
 	win, pipeline, scene, cam := prepareScene()
 
@@ -33,6 +36,8 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 	res := hg.NewPipelineResources()
 
 	sphereRef, shader := createSphereRefAndRes(res)
+	boxRef := createBoxRef(res)
+	models := make(map[string]*hg.ModelRef)
 
 	// light setup
 	hg.CreateSpotLightWithDiffuseDiffuseIntensitySpecularSpecularIntensityPriorityShadowTypeShadowBias(scene, hg.TransformationMat4(hg.NewVec3WithXYZ(-8.8, 21.7, -8.8), hg.Deg3(60, 45, 0)), 0, hg.Deg(5), hg.Deg(30), hg.ColorGetWhite(), 1, hg.ColorGetWhite(), 1, 0, hg.LSTMap, 0.000005)
@@ -44,7 +49,12 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 
 	rendererPool := make(map[string]*obj, 0)
 	for _, object := range *engineSingletone.ObjectPool {
-		sphereMat := hg.CreateMaterialWithValueName0Value0ValueName1Value1(
+		// planes are infinite and would hide the scene
+		if _, ok := object.(*objects.Plane); ok {
+			continue
+		}
+
+		objectMat := hg.CreateMaterialWithValueName0Value0ValueName1Value1(
 			shader,
 			"uDiffuseColor",
 			hg.NewVec4WithXYZ(float32(rand.Intn(100))/100, float32(rand.Intn(100))/100, float32(rand.Intn(100))/100),
@@ -52,8 +62,20 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 			hg.NewVec4WithXYZ(1, 0.8, 0),
 		)
 
+		var transform *hg.Transform
+		switch o := object.(type) {
+		case *objects.Box:
+			transform = newBox(scene, boxRef, objectMat, o.GetHalfExtents())
+		case *objects.Capsule:
+			transform = newObjectNode(scene, createCapsuleRef(res, models, o.GetRadius(), o.GetHalfHeight()), objectMat)
+		case *objects.Cylinder:
+			transform = newObjectNode(scene, createCylinderRef(res, models, o.GetRadius(), o.GetHalfHeight()), objectMat)
+		default:
+			transform = newObjectNode(scene, sphereRef, objectMat)
+		}
+
 		rendererPool[object.GetId()] = &obj{
-			transform: newSphere(scene, sphereRef, sphereMat),
+			transform: transform,
 			object:    object,
 		}
 	}
@@ -103,7 +125,7 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 	// main loop
 	frame := 0
 
-	for i := 0; i < 1024; i++ {
+	for i := 0; i < spheres; i++ {
 		newSphereInPool_TEMP(engineSingletone, &rendererPool, scene, sphereRef, shader)
 	}
 	log.Printf("Objects in pool on start: %d", len(*engineSingletone.ObjectPool))
@@ -116,6 +138,7 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 		if time.Since(timer) > time.Second*60 {
 			log.Printf("Objects in pool: %d", len(*engineSingletone.ObjectPool))
 			log.Printf("FPM: %d", frame)
+			log.Printf("Energy: %.3f J", integration.Energy(*engineSingletone.ObjectPool, constants.Gravity))
 
 			if len(*engineSingletone.ObjectPool) >= 32768 {
 				log.Printf("Simulation is too long, stopping...")
@@ -137,9 +160,8 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 			updateObjectOnRenderer(object)
 		}
 
-		engineSingletone.ProcessCollisions()
-
 		dt := hg.TickClock()
+		engineSingletone.Step(float64(hg.TimeToSecF(dt)))
 		scene.Update(dt)
 
 		viewID := uint16(0)
@@ -166,29 +188,50 @@ func Start(engineSingletone *st.Engine, cancel context.CancelFunc) {
 
 func createSphereRefAndRes(res *hg.PipelineResources) (*hg.ModelRef, *hg.PipelineProgramRef) {
 	vtxLayout := hg.VertexLayoutPosFloatNormUInt8()
-	sphereMdl := hg.CreateSphereModel(vtxLayout, sphereRadius, 8, 16)
+	sphereMdl := hg.CreateSphereModel(vtxLayout, sc.SPHERE_RADIUS, 8, 16)
 
 	return res.AddModel("sphere", sphereMdl),
 		hg.LoadPipelineProgramRefFromFile("resources_compiled/core/shader/default.hps", res, hg.GetForwardPipelineInfo())
 }
 
-func newSphereInPool_TEMP(engineSingletone *st.Engine, rendererPool *map[string]*obj, scene *hg.Scene, sphereRef *hg.ModelRef, shader *hg.PipelineProgramRef) {
-	id := fmt.Sprintf("%s_%d", time.Now().Format(time.RFC3339), len(*engineSingletone.ObjectPool))
+// unit cube, scaled to the box size when a node is created
+func createBoxRef(res *hg.PipelineResources) *hg.ModelRef {
+	vtxLayout := hg.VertexLayoutPosFloatNormUInt8()
+	boxMdl := hg.CreateCubeModel(vtxLayout, 1, 1, 1)
+
+	return res.AddModel("box", boxMdl)
+}
 
-	posX := float64(rand.Intn(50) - 25)
-	posY := float64(rand.Intn(50) - 25)
-	posZ := float64(rand.Intn(50) - 25)
+// capsules and cylinders can not be scaled, so there is a model for every size
+func createCapsuleRef(res *hg.PipelineResources, models map[string]*hg.ModelRef, radius, halfHeight float64) *hg.ModelRef {
+	name := fmt.Sprintf("capsule_%g_%g", radius, halfHeight)
+	if ref, ok := models[name]; ok {
+		return ref
+	}
 
-	maxInitialSpeed := 0.1                             // Например, максимальная начальная скорость 0.1
-	forceX := (rand.Float64()*2 - 1) * maxInitialSpeed // Диапазон [-maxInitialSpeed, maxInitialSpeed)
-	forceY := (rand.Float64()*2 - 1) * maxInitialSpeed
-	forceZ := (rand.Float64()*2 - 1) * maxInitialSpeed
+	vtxLayout := hg.VertexLayoutPosFloatNormUInt8()
+	capsuleMdl := hg.CreateCapsuleModel(vtxLayout, float32(radius), float32(2*(halfHeight+radius)), 16, 8)
 
-	sphere := objects.NewSphere(sphereRadius, id)
-	sphere.SetPosition(vector.Vector3D{X: posX, Y: posY, Z: posZ})
-	sphere.ApplyVelocity(vector.Vector3D{X: forceX, Y: forceY, Z: forceZ})
+	models[name] = res.AddModel(name, capsuleMdl)
+	return models[name]
+}
 
-	engineSingletone.AddObject(&sphere)
+func createCylinderRef(res *hg.PipelineResources, models map[string]*hg.ModelRef, radius, halfHeight float64) *hg.ModelRef {
+	name := fmt.Sprintf("cylinder_%g_%g", radius, halfHeight)
+	if ref, ok := models[name]; ok {
+		return ref
+	}
+
+	vtxLayout := hg.VertexLayoutPosFloatNormUInt8()
+	cylinderMdl := hg.CreateCylinderModel(vtxLayout, float32(radius), float32(2*halfHeight), 16)
+
+	models[name] = res.AddModel(name, cylinderMdl)
+	return models[name]
+}
+
+func newSphereInPool_TEMP(engineSingletone *st.Engine, rendererPool *map[string]*obj, scene *hg.Scene, sphereRef *hg.ModelRef, shader *hg.PipelineProgramRef) {
+	id := fmt.Sprintf("%s_%d", time.Now().Format(time.RFC3339), len(*engineSingletone.ObjectPool))
+	sphere := sc.AddSphere(engineSingletone, id)
 
 	sphereMat := hg.CreateMaterialWithValueName0Value0ValueName1Value1(
 		shader,
@@ -199,8 +242,8 @@ func newSphereInPool_TEMP(engineSingletone *st.Engine, rendererPool *map[string]
 	)
 
 	sphereRenderer := &obj{
-		transform: newSphere(scene, sphereRef, sphereMat),
-		object:    &sphere,
+		transform: newObjectNode(scene, sphereRef, sphereMat),
+		object:    sphere,
 	}
 
 	pos, err := sphere.GetPosition()
@@ -213,20 +256,33 @@ func newSphereInPool_TEMP(engineSingletone *st.Engine, rendererPool *map[string]
 	(*rendererPool)[sphere.GetId()] = sphereRenderer
 }
 
-func newSphere(scene *hg.Scene, sphereRef *hg.ModelRef, sphereMat *hg.Material) *hg.Transform {
+func newObjectNode(scene *hg.Scene, modelRef *hg.ModelRef, objectMat *hg.Material) *hg.Transform {
 	node := hg.CreateObjectWithSliceOfMaterials(
 		scene,
 		hg.TranslationMat4(hg.NewVec3WithXYZ(0.1, 0.1, 0.1)),
-		sphereRef,
-		hg.GoSliceOfMaterial{sphereMat},
+		modelRef,
+		hg.GoSliceOfMaterial{objectMat},
 	)
 
 	return node.GetTransform()
 }
 
-func updateObjectOnRenderer(object *obj) {
-	object.object.Update()
+func newBox(scene *hg.Scene, boxRef *hg.ModelRef, boxMat *hg.Material, halfExtents vector.Vector3D) *hg.Transform {
+	node := hg.CreateObjectWithSliceOfMaterials(
+		scene,
+		hg.TransformationMat4WithScale(
+			hg.NewVec3WithXYZ(0.1, 0.1, 0.1),
+			hg.NewVec3WithXYZ(0, 0, 0),
+			hg.NewVec3WithXYZ(float32(2*halfExtents.X), float32(2*halfExtents.Y), float32(2*halfExtents.Z)),
+		),
+		boxRef,
+		hg.GoSliceOfMaterial{boxMat},
+	)
 
+	return node.GetTransform()
+}
+
+func updateObjectOnRenderer(object *obj) {
 	pos, err := object.object.GetPosition()
 	if err != nil {
 		log.Printf("error: %v", err)
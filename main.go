@@ -2,18 +2,38 @@ package main
 
 import (
 	"BachelorThesis/engine"
+	"BachelorThesis/engine/config"
 	"BachelorThesis/engine/constants"
+	"BachelorThesis/engine/headless"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"runtime"
+	"time"
 )
 
 func main() {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
-	var algorithm, secondaryAlgorithm, resolveAlgorithm, pipeline string
+	// flags or a config file run one simulation, without them the menu asks for the options
+	cfg, interactive, err := config.Parse(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+	if !interactive {
+		runConfig(cfg)
+		return
+	}
+
+	var algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, pipeline string
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -26,11 +46,13 @@ func main() {
 			fmt.Printf("\t1. %s%s\n", constants.SaP, constants.N)
 			fmt.Printf("\t2. %s%s\n", constants.SaP, constants.PT)
 			fmt.Printf("\t3. %s%s\n", constants.SaP, constants.PNT)
-			fmt.Printf("Enter a number to choose an algorithm (1/2/3): ")
+			fmt.Printf("\t4. %s%s\n", constants.BVH, constants.N)
+			fmt.Printf("\t5. %s%s\n", constants.BVH, constants.PT)
+			fmt.Printf("Enter a number to choose an algorithm (1/2/3/4/5): ")
 			algo := 0
 			for algo == 0 {
 				_, err := fmt.Scanln(&algo)
-				if err != nil || algo < 1 || algo > 3 {
+				if err != nil || algo < 1 || algo > 5 {
 					algo = 0
 					continue
 				}
@@ -45,6 +67,12 @@ func main() {
 			case 3:
 				algorithm = constants.SaP
 				constants.AlgoType = constants.PNT
+			case 4:
+				algorithm = constants.BVH
+				constants.AlgoType = constants.N
+			case 5:
+				algorithm = constants.BVH
+				constants.AlgoType = constants.PT
 			default:
 				log.Panicf("Unknown algorithm: %d", algo)
 				continue
@@ -53,11 +81,13 @@ func main() {
 			fmt.Printf("Avaliable secondary algorithms:\n")
 			fmt.Printf("\t1. %s%s\n", constants.SAT, constants.N)
 			fmt.Printf("\t2. %s%s\n", constants.SAT, constants.PT)
-			fmt.Printf("Enter a number to choose an algorithm (1/2): ")
+			fmt.Printf("\t3. %s%s\n", constants.GJK, constants.N)
+			fmt.Printf("\t4. %s + %s%s\n", constants.GJK, constants.EPA, constants.N)
+			fmt.Printf("Enter a number to choose an algorithm (1/2/3/4): ")
 			secAlgo := 0
 			for secAlgo == 0 {
 				_, err := fmt.Scanln(&secAlgo)
-				if err != nil || secAlgo < 1 || secAlgo > 2 {
+				if err != nil || secAlgo < 1 || secAlgo > 4 {
 					secAlgo = 0
 					continue
 				}
@@ -69,6 +99,12 @@ func main() {
 			case 2:
 				secondaryAlgorithm = constants.SAT
 				constants.SecondaryAlgoType = constants.PT
+			case 3:
+				secondaryAlgorithm = constants.GJK
+				constants.SecondaryAlgoType = constants.N
+			case 4:
+				secondaryAlgorithm = constants.EPA
+				constants.SecondaryAlgoType = constants.N
 			default:
 				log.Panicf("Unknown algorithm: %d", secAlgo)
 				continue
@@ -77,11 +113,14 @@ func main() {
 			fmt.Printf("Avaliable resolve algorithms:\n")
 			fmt.Printf("\t1. %s%s\n", constants.PGS, constants.N)
 			fmt.Printf("\t2. %s%s\n", constants.PGS, constants.PNT)
-			fmt.Printf("Enter a number to choose an algorithm (1/2): ")
+			fmt.Printf("\t3. %s%s\n", constants.TGS, constants.N)
+			fmt.Printf("\t4. %s%s\n", constants.MSI, constants.N)
+			fmt.Printf("\t5. %s%s\n", constants.LCP, constants.N)
+			fmt.Printf("Enter a number to choose an algorithm (1/2/3/4/5): ")
 			resAlgo := 0
 			for resAlgo == 0 {
 				_, err := fmt.Scanln(&resAlgo)
-				if err != nil || resAlgo < 1 || resAlgo > 2 {
+				if err != nil || resAlgo < 1 || resAlgo > 5 {
 					resAlgo = 0
 					continue
 				}
@@ -93,11 +132,45 @@ func main() {
 			case 2:
 				resolveAlgorithm = constants.PGS
 				constants.ResolveAlgoType = constants.PNT
+			case 3:
+				resolveAlgorithm = constants.TGS
+				constants.ResolveAlgoType = constants.N
+			case 4:
+				resolveAlgorithm = constants.MSI
+				constants.ResolveAlgoType = constants.N
+			case 5:
+				resolveAlgorithm = constants.LCP
+				constants.ResolveAlgoType = constants.N
 			default:
 				log.Panicf("Unknown algorithm: %d", resAlgo)
 				continue
 			}
 
+			fmt.Printf("Avaliable integrators:\n")
+			fmt.Printf("\t1. %s\n", constants.Euler)
+			fmt.Printf("\t2. %s\n", constants.Verlet)
+			fmt.Printf("\t3. %s\n", constants.RK4)
+			fmt.Printf("Enter a number to choose an integrator (1/2/3): ")
+			integ := 0
+			for integ == 0 {
+				_, err := fmt.Scanln(&integ)
+				if err != nil || integ < 1 || integ > 3 {
+					integ = 0
+					continue
+				}
+			}
+			switch integ {
+			case 1:
+				integrator = constants.Euler
+			case 2:
+				integrator = constants.Verlet
+			case 3:
+				integrator = constants.RK4
+			default:
+				log.Panicf("Unknown integrator: %d", integ)
+				continue
+			}
+
 			pipeline = ""
 			for pipeline != "y" && pipeline != "n" {
 				fmt.Printf("Would you like to use parallel pipeline? (y/n): ")
@@ -109,8 +182,25 @@ func main() {
 				constants.Pipeline = constants.SequentialPipeline
 			}
 
+			worldBox := ""
+			for worldBox != "y" && worldBox != "n" {
+				fmt.Printf("Would you like to enclose the scene in a world box? (y/n): ")
+				fmt.Scanln(&worldBox)
+			}
+			constants.WorldBox = worldBox == "y"
+
+			constants.LCPOracle = false
+			if resolveAlgorithm != constants.LCP {
+				oracle := ""
+				for oracle != "y" && oracle != "n" {
+					fmt.Printf("Would you like to compare the resolver with the exact LCP solution? (y/n): ")
+					fmt.Scanln(&oracle)
+				}
+				constants.LCPOracle = oracle == "y"
+			}
+
 			ctx, cancel = context.WithCancel(context.Background())
-			go engine.Run(algorithm, secondaryAlgorithm, resolveAlgorithm, ctx, cancel)
+			go engine.Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, config.DEFAULT_OBJECTS, ctx, cancel)
 		}
 
 		fmt.Printf("\n ===== ENTER A COMMAND  =====\n")
@@ -130,3 +220,30 @@ func main() {
 
 	log.Printf("Simulation ended")
 }
+
+// runConfig runs the simulation chosen by the flags or the config file until its duration
+// is over, the window is closed or the process is interrupted
+func runConfig(cfg config.Config) {
+	algorithm, secondaryAlgorithm, resolveAlgorithm, integrator := cfg.Apply()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if cfg.Headless {
+		options := headless.Options{Objects: cfg.Objects, Frames: cfg.Frames, Duration: time.Duration(cfg.Duration)}
+		if _, err := headless.Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, options, ctx); err != nil {
+			log.Fatalf("Headless simulation failed: %v", err)
+		}
+		return
+	}
+
+	if cfg.Duration > 0 {
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeout(ctx, time.Duration(cfg.Duration))
+		defer stop()
+	}
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	engine.Run(algorithm, secondaryAlgorithm, resolveAlgorithm, integrator, cfg.Objects, ctx, stop)
+}